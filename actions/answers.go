@@ -0,0 +1,158 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/burtonr/ofc-wizard/types"
+	"gopkg.in/AlecAivazis/survey.v1"
+)
+
+// Each answer is taken from the first of these that provides it:
+//
+//  1. --set key=value flags
+//  2. OFC_WIZARD_<KEY> environment variables
+//  3. the config file (locked values are used, defaults are offered)
+//  4. the existing init.yml (offered as the default)
+//  5. the built-in default of the question
+//
+// Flags, environment variables and locked values answer the question without prompting
+var envPrefix = "OFC_WIZARD_"
+
+var (
+	// answerOverrides holds the values given with the --set flag
+	answerOverrides = map[string]string{}
+	// existingYaml holds the values loaded from an existing init.yml
+	existingYaml *types.InitYaml
+)
+
+// existingValues maps each answer key to its value in an existing init.yml
+var existingValues = map[string]func(*types.InitYaml) interface{}{
+	orchestrationKey:        func(y *types.InitYaml) interface{} { return y.Orchestration },
+	rootDomainKey:           func(y *types.InitYaml) interface{} { return y.RootDomain },
+	registryKey:             func(y *types.InitYaml) interface{} { return y.Registry },
+	ecrRegionKey:            func(y *types.InitYaml) interface{} { return y.ECRConfig.ECRRegion },
+	scmKey:                  func(y *types.InitYaml) interface{} { return y.SCM },
+	enableOAuthKey:          func(y *types.InitYaml) interface{} { return y.EnableOAuth },
+	githubAppIDKey:          func(y *types.InitYaml) interface{} { return y.Github.AppID },
+	gitlabInstanceKey:       func(y *types.InitYaml) interface{} { return y.GitLab.GitLabInstance },
+	oauthClientIDKey:        func(y *types.InitYaml) interface{} { return y.OAuth.ClientID },
+	oauthProviderBaseURLKey: func(y *types.InitYaml) interface{} { return y.OAuth.OAuthProviderBaseURL },
+	customStorageKey:        func(y *types.InitYaml) interface{} { return y.S3.S3URL != "" && y.S3.S3URL != defaultS3URL },
+	s3URLKey:                func(y *types.InitYaml) interface{} { return y.S3.S3URL },
+	s3RegionKey:             func(y *types.InitYaml) interface{} { return y.S3.S3Region },
+	s3BucketKey:             func(y *types.InitYaml) interface{} { return y.S3.S3Bucket },
+	s3TLSKey:                func(y *types.InitYaml) interface{} { return y.S3.S3TLS },
+	dnsProviderKey:          func(y *types.InitYaml) interface{} { return dnsFriendlyName(y.TLSConfig.DNSService) },
+	tlsKey:                  func(y *types.InitYaml) interface{} { return y.TLS },
+	tlsEmailKey:             func(y *types.InitYaml) interface{} { return y.TLSConfig.Email },
+	tlsIssuerTypeKey:        func(y *types.InitYaml) interface{} { return y.TLSConfig.IssuerType },
+	gcpProjectIDKey:         func(y *types.InitYaml) interface{} { return y.TLSConfig.ProjectID },
+	awsRegionKey:            func(y *types.InitYaml) interface{} { return y.TLSConfig.Region },
+	awsAccessKeyIDKey:       func(y *types.InitYaml) interface{} { return y.TLSConfig.AccessKeyID },
+	auditToKey:              func(y *types.InitYaml) interface{} { return auditDestination(y.Slack.URL) },
+	auditURLKey:             func(y *types.InitYaml) interface{} { return customAuditURL(y.Slack.URL) },
+	customersURLKey:         func(y *types.InitYaml) interface{} { return y.CustomersURL },
+	customersSourceKey:      func(y *types.InitYaml) interface{} { return customersSource(*y) },
+	customersFileKey:        func(y *types.InitYaml) interface{} { return customersFile(*y) },
+	enableDockerfileKey:     func(y *types.InitYaml) interface{} { return y.EnableDockerFile },
+	scaleToZeroKey:          func(y *types.InitYaml) interface{} { return y.ScaleToZero },
+	ofcVersionKey:           func(y *types.InitYaml) interface{} { return y.OpenFaaSCloudVersion },
+	networkPoliciesKey:      func(y *types.InitYaml) interface{} { return y.NetworkPolicies },
+	ingressKey:              func(y *types.InitYaml) interface{} { return y.Ingress },
+	buildBranchKey:          func(y *types.InitYaml) interface{} { return y.BuildBranch },
+	customTemplatesKey:      func(y *types.InitYaml) interface{} { return strings.Join(y.Deployment.CustomTemplates, ", ") },
+}
+
+// SetAnswerOverrides parses the key=value pairs given on the command line, to be used
+// as answers without prompting
+func SetAnswerOverrides(values []string) error {
+	for _, v := range values {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid value %q, expected key=value", v)
+		}
+
+		key := strings.TrimSpace(parts[0])
+		if !isAnswerKey(key) {
+			return fmt.Errorf("unknown answer key %q", key)
+		}
+		answerOverrides[key] = parts[1]
+	}
+	return nil
+}
+
+func isAnswerKey(key string) bool {
+	for _, k := range answerKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// envName returns the environment variable that answers the given key
+func envName(key string) string {
+	return envPrefix + strings.ToUpper(key)
+}
+
+// parseAnswer converts the raw string value to the type of answer the prompt would produce
+func parseAnswer(prompt survey.Prompt, raw string) (interface{}, error) {
+	switch prompt.(type) {
+	case *survey.Confirm:
+		return strconv.ParseBool(raw)
+	case *survey.MultiSelect:
+		return splitList(raw), nil
+	default:
+		return raw, nil
+	}
+}
+
+// splitList splits a comma separated value, ignoring empty entries
+func splitList(raw string) []string {
+	values := []string{}
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// overrideValue returns the raw value for the key given by a flag or environment variable
+func overrideValue(key string) (string, bool) {
+	if v, ok := answerOverrides[key]; ok {
+		return v, true
+	}
+	return os.LookupEnv(envName(key))
+}
+
+// existingValue returns the non-empty value of the key from an existing init.yml
+func existingValue(key string) (interface{}, bool) {
+	getter, ok := existingValues[key]
+	if existingYaml == nil || !ok {
+		return nil, false
+	}
+
+	switch v := getter(existingYaml).(type) {
+	case string:
+		return v, v != ""
+	case bool:
+		return v, v
+	case []string:
+		return v, len(v) > 0
+	}
+	return nil, false
+}
+
+// dnsFriendlyName returns the display name of the DNS provider with the given secret name
+func dnsFriendlyName(name string) string {
+	for _, p := range []dnsProvider{digOceanDNS, gCloudDNS, awsDNS} {
+		if p.Name == name {
+			return p.FriendlyName
+		}
+	}
+	return ""
+}
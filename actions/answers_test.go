@@ -0,0 +1,123 @@
+package actions
+
+import (
+	"os"
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+	"github.com/spf13/viper"
+	"gopkg.in/AlecAivazis/survey.v1"
+)
+
+// resolveAnswer returns the answer for the key the way askOne chooses it, and whether the
+// question would be asked
+func resolveAnswer(key string, prompt survey.Prompt) (interface{}, string, bool, error) {
+	value, source, err := presetAnswer(key, prompt, nil)
+	if err != nil || source != "" {
+		return value, source, false, err
+	}
+
+	value, source, _ = defaultAnswer(key, prompt)
+	return value, source, true, nil
+}
+
+func Test_answerPrecedence(t *testing.T) {
+	cases := []struct {
+		name       string
+		flag       string
+		env        string
+		locked     string
+		configured string
+		existing   string
+		wantValue  interface{}
+		wantSource string
+		wantAsked  bool
+	}{
+		{"flag wins", "flag.com", "env.com", "locked.com", "default.com", "existing.com", "flag.com", sourceFlag, false},
+		{"environment", "", "env.com", "locked.com", "default.com", "existing.com", "env.com", sourceEnv + " OFC_WIZARD_ROOT_DOMAIN", false},
+		{"locked", "", "", "locked.com", "default.com", "existing.com", "locked.com", sourceLocked, false},
+		{"config default", "", "", "", "default.com", "existing.com", "default.com", sourceConfig, true},
+		{"existing init.yml", "", "", "", "", "existing.com", "existing.com", sourceExisting, true},
+		{"built-in default", "", "", "", "", "", nil, "", true},
+	}
+
+	for _, c := range cases {
+		viper.Reset()
+		answerOverrides = map[string]string{}
+		os.Unsetenv(envName(rootDomainKey))
+		existingYaml = &types.InitYaml{RootDomain: c.existing}
+
+		if c.flag != "" {
+			if err := SetAnswerOverrides([]string{rootDomainKey + "=" + c.flag}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if c.env != "" {
+			os.Setenv(envName(rootDomainKey), c.env)
+		}
+		if c.locked != "" {
+			viper.Set(lockedSection+"."+rootDomainKey, c.locked)
+		}
+		if c.configured != "" {
+			viper.Set(defaultsSection+"."+rootDomainKey, c.configured)
+		}
+
+		value, source, asked, err := resolveAnswer(rootDomainKey, &survey.Input{})
+		if err != nil {
+			t.Errorf("%s: want no error, got %s", c.name, err)
+		}
+		if value != c.wantValue || source != c.wantSource || asked != c.wantAsked {
+			t.Errorf("%s: want %v from %q (asked %t), got %v from %q (asked %t)", c.name, c.wantValue, c.wantSource, c.wantAsked, value, source, asked)
+		}
+	}
+
+	viper.Reset()
+	answerOverrides = map[string]string{}
+	os.Unsetenv(envName(rootDomainKey))
+	existingYaml = nil
+}
+
+func Test_answerPrecedence_ParsesBool(t *testing.T) {
+	os.Setenv(envName(tlsKey), "true")
+	defer os.Unsetenv(envName(tlsKey))
+
+	value, _, _, err := resolveAnswer(tlsKey, &survey.Confirm{})
+	if err != nil || value != true {
+		t.Errorf("want true, got %v %v", value, err)
+	}
+}
+
+func Test_SetAnswerOverrides(t *testing.T) {
+	defer func() { answerOverrides = map[string]string{} }()
+
+	if err := SetAnswerOverrides([]string{" root_domain =example.com", "registry=docker.io/name=x"}); err != nil {
+		t.Fatal(err)
+	}
+	if answerOverrides[rootDomainKey] != "example.com" || answerOverrides[registryKey] != "docker.io/name=x" {
+		t.Errorf("want the values split at the first =, got %v", answerOverrides)
+	}
+}
+
+func Test_SetAnswerOverrides_Malformed(t *testing.T) {
+	defer func() { answerOverrides = map[string]string{} }()
+
+	for _, value := range []string{"root_domain", "=example.com", "not_a_key=1", ""} {
+		if err := SetAnswerOverrides([]string{value}); err == nil {
+			t.Errorf("%q: want an error", value)
+		}
+	}
+}
+
+func Test_parseAnswer(t *testing.T) {
+	if v, err := parseAnswer(&survey.Confirm{}, "false"); err != nil || v != false {
+		t.Errorf("want false, got %v %v", v, err)
+	}
+	if _, err := parseAnswer(&survey.Confirm{}, "maybe"); err == nil {
+		t.Error("want an error for a value which is not a bool")
+	}
+
+	v, _ := parseAnswer(&survey.MultiSelect{}, "a, ,b")
+	if list, ok := v.([]string); !ok || len(list) != 2 {
+		t.Errorf("want [a b], got %v", v)
+	}
+}
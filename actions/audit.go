@@ -0,0 +1,191 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+var (
+	defaultAuditURL = "http://gateway.openfaas:8080/function/echo"
+	slackHost       = "hooks.slack.com"
+)
+
+// Where the audit trail is posted to
+var (
+	auditToEcho  = "echo"
+	auditToSlack = "slack"
+	auditToHTTP  = "http"
+)
+
+var slackWebhookPattern = regexp.MustCompile(`^https://hooks\.slack\.com/services/T[A-Za-z0-9]+/B[A-Za-z0-9]+/[A-Za-z0-9]+$`)
+
+// auditHTTPClient is used to send the test audit event
+var auditHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// auditDestination returns where the audit trail URL posts to
+func auditDestination(auditURL string) string {
+	switch {
+	case auditURL == "" || auditURL == defaultAuditURL:
+		return auditToEcho
+	case isSlackWebhook(auditURL):
+		return auditToSlack
+	}
+	return auditToHTTP
+}
+
+// customAuditURL returns the audit trail URL when it is not the built-in echo function
+func customAuditURL(auditURL string) string {
+	if auditDestination(auditURL) == auditToEcho {
+		return ""
+	}
+	return auditURL
+}
+
+func isSlackWebhook(auditURL string) bool {
+	u, err := url.Parse(auditURL)
+	return err == nil && strings.ToLower(u.Host) == slackHost
+}
+
+// printableAuditURL hides the token of a Slack webhook URL, which lets anyone post to the channel
+func printableAuditURL(auditURL string) string {
+	if isSlackWebhook(auditURL) {
+		return "https://" + slackHost + "/services/********"
+	}
+	return auditURL
+}
+
+// validateSlackWebhook is a survey validator for a Slack incoming webhook URL
+func validateSlackWebhook(val interface{}) error {
+	str, ok := val.(string)
+	if !ok {
+		return errors.New("The Slack webhook URL must be a string")
+	}
+	if !slackWebhookPattern.MatchString(strings.TrimSpace(str)) {
+		return errors.New("The Slack webhook URL must look like https://hooks.slack.com/services/T000/B000/XXXX, create one at https://api.slack.com/messaging/webhooks")
+	}
+	return nil
+}
+
+// validateAuditURL is a survey validator for a generic audit trail endpoint
+func validateAuditURL(val interface{}) error {
+	str, ok := val.(string)
+	if !ok {
+		return errors.New("The audit trail URL must be a string")
+	}
+
+	u, err := url.Parse(strings.TrimSpace(str))
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return errors.New("The audit trail URL must be an http or https URL (eg: https://audit.example.com/events)")
+	}
+	return nil
+}
+
+// auditURLValidator returns the validator for the destination of the audit trail URL
+func auditURLValidator(auditURL string) func(interface{}) error {
+	if isSlackWebhook(auditURL) {
+		return validateSlackWebhook
+	}
+	return validateAuditURL
+}
+
+// checkAudit returns an error when the audit trail URL of the init.yml is not valid
+func checkAudit(yml types.InitYaml) []error {
+	errs := []error{}
+	if yml.Slack.URL == "" {
+		return errs
+	}
+
+	if err := auditURLValidator(yml.Slack.URL)(yml.Slack.URL); err != nil {
+		errs = append(errs, fmt.Errorf("slack.url: %s", err.Error()))
+	}
+	return errs
+}
+
+// sampleAuditEvent returns the event posted by test-audit
+func sampleAuditEvent(message string) types.AuditEvent {
+	return types.AuditEvent{
+		Source:  "ofc-wizard",
+		Message: message,
+		Owner:   "openfaas",
+		Repo:    "test-audit",
+	}
+}
+
+// auditPayload returns the body to post the event to the audit trail URL. A Slack incoming
+// webhook only accepts a message, so the event is written as its text
+func auditPayload(auditURL string, event types.AuditEvent) ([]byte, error) {
+	if isSlackWebhook(auditURL) {
+		text := fmt.Sprintf("%s: %s", event.Source, event.Message)
+		if event.Owner != "" {
+			text = fmt.Sprintf("%s (%s/%s)", text, event.Owner, event.Repo)
+		}
+		return json.Marshal(types.SlackMessage{Text: text})
+	}
+	return json.Marshal(event)
+}
+
+// postAuditEvent posts the event to the audit trail URL, returning the response body
+func postAuditEvent(auditURL string, event types.AuditEvent) (string, error) {
+	body, err := auditPayload(auditURL, event)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := auditHTTPClient.Post(auditURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			urlErr.URL = printableAuditURL(urlErr.URL)
+		}
+		return "", err
+	}
+	defer res.Body.Close()
+
+	data, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return "", fmt.Errorf("%s returned %s: %s", printableAuditURL(auditURL), res.Status, strings.TrimSpace(string(data)))
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// TestAudit posts a sample audit event to the URL, or to the audit trail URL of the init.yml
+// at the path when no URL is given, and prints the response
+func TestAudit(path string, auditURL string, message string, out io.Writer) {
+	if err := sendTestAudit(path, auditURL, message, out); err != nil {
+		exitWithError(err)
+	}
+}
+
+func sendTestAudit(path string, auditURL string, message string, out io.Writer) error {
+	if auditURL == "" {
+		auditURL = LoadInitFileFrom(path).Slack.URL
+		if auditURL == "" || auditURL == defaultAuditURL {
+			return fmt.Errorf("%s uses the echo function inside the cluster, give a reachable --url such as http://127.0.0.1:8080/function/echo", path)
+		}
+	}
+
+	if err := auditURLValidator(auditURL)(auditURL); err != nil {
+		return err
+	}
+
+	response, err := postAuditEvent(auditURL, sampleAuditEvent(message))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Posted a test audit event to the %s endpoint %s\n", auditDestination(auditURL), printableAuditURL(auditURL))
+	if response != "" {
+		fmt.Fprintln(out, response)
+	}
+	return nil
+}
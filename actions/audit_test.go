@@ -0,0 +1,222 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+var testSlackWebhook = "https://hooks.slack.com/services/T000/B000/XXXX"
+
+// auditRequest is a request received by the fake audit trail
+type auditRequest struct {
+	Path        string
+	ContentType string
+	Body        string
+}
+
+// useFakeAuditTrail starts an endpoint replying with the status and body, and sends the
+// requests for hooks.slack.com to it too. The requests received are recorded
+func useFakeAuditTrail(status int, reply string) (*httptest.Server, *[]auditRequest, func()) {
+	requests := &[]auditRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		*requests = append(*requests, auditRequest{Path: r.URL.Path, ContentType: r.Header.Get("Content-Type"), Body: string(body)})
+		w.WriteHeader(status)
+		w.Write([]byte(reply))
+	}))
+
+	client := auditHTTPClient
+	auditHTTPClient = &http.Client{Transport: slackTransport{Host: strings.TrimPrefix(server.URL, "http://")}}
+
+	return server, requests, func() {
+		server.Close()
+		auditHTTPClient = client
+	}
+}
+
+// slackTransport sends the requests for hooks.slack.com to the host over plain http
+type slackTransport struct {
+	Host string
+}
+
+func (t slackTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.Host == slackHost {
+		r.URL.Scheme = "http"
+		r.URL.Host = t.Host
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func Test_validateSlackWebhook(t *testing.T) {
+	cases := map[interface{}]bool{
+		testSlackWebhook:                                 true,
+		" " + testSlackWebhook + " ":                     true,
+		"http://hooks.slack.com/services/T000/B000/XXXX": false,
+		"https://hooks.slack.com/services/T000/B000":     false,
+		"https://hooks.slack.com/workflows/T000/A000/1":  false,
+		"https://example.com/services/T000/B000/XXXX":    false,
+		"": false,
+		1:  false,
+	}
+
+	for val, valid := range cases {
+		if err := validateSlackWebhook(val); (err == nil) != valid {
+			t.Errorf("%v: want valid %t, got %v", val, valid, err)
+		}
+	}
+}
+
+func Test_auditPayload(t *testing.T) {
+	event := sampleAuditEvent("hello")
+
+	slack, err := auditPayload(testSlackWebhook, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"text":"ofc-wizard: hello (openfaas/test-audit)"}`; string(slack) != want {
+		t.Errorf("want %s, got %s", want, slack)
+	}
+
+	body, err := auditPayload("https://audit.example.com/events", event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := types.AuditEvent{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != event {
+		t.Errorf("want the event %+v, got %s", event, body)
+	}
+}
+
+func Test_postAuditEvent(t *testing.T) {
+	server, requests, restore := useFakeAuditTrail(http.StatusOK, "logged\n")
+	defer restore()
+
+	response, err := postAuditEvent(server.URL+"/function/echo", sampleAuditEvent("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response != "logged" {
+		t.Errorf("want the response logged, got %q", response)
+	}
+
+	if len(*requests) != 1 {
+		t.Fatalf("want 1 request, got %d", len(*requests))
+	}
+	r := (*requests)[0]
+	if r.Path != "/function/echo" || r.ContentType != "application/json" || !strings.Contains(r.Body, `"message":"hello"`) {
+		t.Errorf("unexpected request %+v", r)
+	}
+}
+
+func Test_postAuditEvent_Slack(t *testing.T) {
+	_, requests, restore := useFakeAuditTrail(http.StatusOK, "ok")
+	defer restore()
+
+	if _, err := postAuditEvent(testSlackWebhook, sampleAuditEvent("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 1 || (*requests)[0].Path != "/services/T000/B000/XXXX" || !strings.HasPrefix((*requests)[0].Body, `{"text":`) {
+		t.Errorf("want a Slack message posted to the webhook, got %+v", *requests)
+	}
+}
+
+func Test_postAuditEvent_Non2xx(t *testing.T) {
+	server, _, restore := useFakeAuditTrail(http.StatusForbidden, "invalid_token")
+	defer restore()
+
+	_, err := postAuditEvent(server.URL, sampleAuditEvent("hello"))
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("want the status and body in the error, got %v", err)
+	}
+
+	_, err = postAuditEvent(testSlackWebhook, sampleAuditEvent("hello"))
+	if err == nil || strings.Contains(err.Error(), "XXXX") {
+		t.Errorf("want an error without the webhook token, got %v", err)
+	}
+}
+
+// writeAuditInit writes an init.yml with the audit trail URL to a temporary directory,
+// returning its path and a function which removes it
+func writeAuditInit(t *testing.T, auditURL string) (string, func()) {
+	dir, err := ioutil.TempDir("", "ofc-wizard")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "init.yml")
+	if err := ioutil.WriteFile(path, []byte("slack:\n  url: "+auditURL+"\n"), 0644); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func Test_sendTestAudit(t *testing.T) {
+	server, requests, restore := useFakeAuditTrail(http.StatusOK, "logged")
+	defer restore()
+
+	path, remove := writeAuditInit(t, server.URL+"/audit")
+	defer remove()
+
+	out := &bytes.Buffer{}
+	if err := sendTestAudit(path, "", "hello", out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Posted a test audit event to the http endpoint " + server.URL + "/audit\nlogged\n"
+	if out.String() != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, out.String())
+	}
+	if len(*requests) != 1 || !strings.Contains((*requests)[0].Body, `"message":"hello"`) {
+		t.Errorf("want the sample event posted, got %+v", *requests)
+	}
+}
+
+func Test_sendTestAudit_Slack(t *testing.T) {
+	_, _, restore := useFakeAuditTrail(http.StatusOK, "ok")
+	defer restore()
+
+	out := &bytes.Buffer{}
+	if err := sendTestAudit("", testSlackWebhook, "hello", out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "XXXX") || !strings.Contains(out.String(), "slack endpoint") {
+		t.Errorf("want the slack endpoint printed without its token, got:\n%s", out.String())
+	}
+}
+
+func Test_sendTestAudit_Rejected(t *testing.T) {
+	_, requests, restore := useFakeAuditTrail(http.StatusOK, "ok")
+	defer restore()
+
+	path, remove := writeAuditInit(t, defaultAuditURL)
+	defer remove()
+
+	cases := map[string]string{
+		"the echo function": "",
+		"not http":          "ftp://audit.example.com/events",
+		"no host":           "https:///events",
+		"invalid webhook":   "https://hooks.slack.com/services/T000",
+	}
+
+	for name, auditURL := range cases {
+		if err := sendTestAudit(path, auditURL, "hello", &bytes.Buffer{}); err == nil {
+			t.Errorf("%s: want an error for %q", name, auditURL)
+		}
+	}
+	if len(*requests) != 0 {
+		t.Errorf("want nothing posted, got %+v", *requests)
+	}
+}
@@ -0,0 +1,128 @@
+package actions
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+var (
+	gitlabWebhookSecretName = "gitlab-webhook-secret"
+	oauthClientSecretName   = "of-client-secret"
+	gitlabOAuthScopes       = []string{"openid", "read_user"}
+	gitlabHookTriggers      = []string{"Push events", "Repository update events"}
+	deployTopic             = "openfaas-cloud"
+)
+
+// checklistSection is a titled list of setup steps
+type checklistSection struct {
+	Title string
+	Steps []string
+}
+
+// gitlabChecklist returns the steps to set up the GitLab instance for the init.yml
+func gitlabChecklist(yml types.InitYaml) []checklistSection {
+	instance := normaliseGitLabInstance(yml.GitLab.GitLabInstance)
+
+	hook := checklistSection{
+		Title: "System hook",
+		Steps: []string{
+			fmt.Sprintf("Sign in to GitLab as an administrator and open Admin Area > System Hooks (%sadmin/hooks)", instance),
+			fmt.Sprintf("Set the URL to `%s`", systemURL(yml, "gitlab-event")),
+			fmt.Sprintf("Set the Secret Token to the value of the %s, %s", gitlabWebhookSecretName, secretLocation(yml, gitlabWebhookSecretName)),
+			fmt.Sprintf("Enable the %s triggers", strings.Join(gitlabHookTriggers, " and ")),
+		},
+	}
+	if yml.TLS {
+		hook.Steps = append(hook.Steps, "Leave Enable SSL verification checked")
+	} else {
+		hook.Steps = append(hook.Steps, "Uncheck Enable SSL verification, as TLS is not enabled")
+	}
+
+	sections := []checklistSection{hook}
+
+	if yml.EnableOAuth {
+		clientIDStep := "Copy the Application ID into `oauth.client_id` of init.yml"
+		if yml.OAuth.ClientID != "" {
+			clientIDStep = fmt.Sprintf("Check the Application ID matches `%s`, the `oauth.client_id` of init.yml", yml.OAuth.ClientID)
+		}
+
+		sections = append(sections, checklistSection{
+			Title: "OAuth application",
+			Steps: []string{
+				fmt.Sprintf("Open Admin Area > Applications (%sadmin/applications) and create a new application named OpenFaaS Cloud", instance),
+				fmt.Sprintf("Set the Redirect URI to `%s`", authURL(yml)),
+				fmt.Sprintf("Check the %s scopes", codeList(gitlabOAuthScopes)),
+				clientIDStep,
+				fmt.Sprintf("Save the Secret as the %s, %s", oauthClientSecretName, secretLocation(yml, oauthClientSecretName)),
+			},
+		})
+	}
+
+	sections = append(sections, checklistSection{
+		Title: "Deploying functions",
+		Steps: []string{
+			fmt.Sprintf("Add the `%s` topic (tag) to each project to deploy, in the project's Settings > General", deployTopic),
+			fmt.Sprintf("Functions are deployed to `%s://<user or group>.%s/<function>`, named after the user or group that owns the project", endpointScheme(yml), yml.RootDomain),
+			"Add each user or group that may deploy functions to the customers list",
+		},
+	})
+	return sections
+}
+
+// secretLocation describes where the value of the secret is kept in the init.yml
+func secretLocation(yml types.InitYaml, name string) string {
+	i := findSecret(yml.Secrets, name)
+	if i < 0 {
+		return fmt.Sprintf("which is not in init.yml yet, add it with `ofc-wizard secrets add %s`", name)
+	}
+
+	sources := []string{}
+	for _, k := range secretKeys(yml.Secrets[i]) {
+		sources = append(sources, k.Source)
+	}
+	if len(sources) == 1 && sources[0] == "literal" {
+		return fmt.Sprintf("a literal in init.yml, shown by `ofc-wizard export secrets --filter %s`", strings.Join(yml.Secrets[i].Filters, ","))
+	}
+	return "read from " + strings.Join(sources, ", ")
+}
+
+func codeList(values []string) string {
+	quoted := []string{}
+	for _, v := range values {
+		quoted = append(quoted, "`"+v+"`")
+	}
+	return strings.Join(quoted, " and ")
+}
+
+// writeChecklist writes the checklist as Markdown
+func writeChecklist(title string, sections []checklistSection, out io.Writer) error {
+	lines := []string{"# " + title}
+	for _, s := range sections {
+		lines = append(lines, "", "## "+s.Title, "")
+		for _, step := range s.Steps {
+			lines = append(lines, "- [ ] "+step)
+		}
+	}
+
+	_, err := fmt.Fprintln(out, strings.Join(lines, "\n"))
+	return err
+}
+
+func gitlabChecklistTitle(yml types.InitYaml) string {
+	return fmt.Sprintf("GitLab setup for OpenFaaS Cloud at %s", yml.RootDomain)
+}
+
+// ExportGitLabChecklist writes the GitLab setup checklist for the init.yml at the path as Markdown
+func ExportGitLabChecklist(path string, out io.Writer) {
+	yml := LoadInitFileFrom(path)
+	if yml.SCM != gitlab {
+		exitWithError(fmt.Errorf("%s uses %s, not %s", path, yml.SCM, gitlab))
+	}
+
+	if err := writeChecklist(gitlabChecklistTitle(*yml), gitlabChecklist(*yml), out); err != nil {
+		exitWithError(err)
+	}
+}
@@ -0,0 +1,46 @@
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func Test_writeChecklist_GitLabGolden(t *testing.T) {
+	cases := []struct {
+		name string
+		yml  types.InitYaml
+	}{
+		{
+			name: "gitlab-checklist-tls-oauth",
+			yml: types.InitYaml{
+				RootDomain:  "example.com",
+				SCM:         gitlab,
+				TLS:         true,
+				EnableOAuth: true,
+				GitLab:      types.GitLab{GitLabInstance: "https://example.com/gitlab"},
+				OAuth:       types.OAuth{ClientID: "abc123", OAuthProviderBaseURL: "https://example.com/gitlab"},
+				Secrets: []types.Secret{
+					{Name: gitlabWebhookSecretName, Literals: []types.Literal{{Name: gitlabWebhookSecretName, Value: "vault://secret/data/ofc#gitlab-webhook-secret"}}},
+				},
+			},
+		},
+		{
+			name: "gitlab-checklist-no-tls",
+			yml: types.InitYaml{
+				RootDomain: "example.com",
+				SCM:        gitlab,
+				GitLab:     types.GitLab{GitLabInstance: "http://gitlab.internal/"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		out := &bytes.Buffer{}
+		if err := writeChecklist(gitlabChecklistTitle(c.yml), gitlabChecklist(c.yml), out); err != nil {
+			t.Fatalf("%s: %s", c.name, err)
+		}
+		checkGolden(t, c.name+".md", out.Bytes())
+	}
+}
@@ -0,0 +1,294 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/AlecAivazis/survey.v1"
+	"gopkg.in/AlecAivazis/survey.v1/core"
+)
+
+// The config file (default $HOME/.ofc-wizard.yaml) may provide a value for any
+// question under one of these sections, keyed by the question's config key:
+//
+//	defaults:
+//	  registry: docker.io/example/
+//	  tls_email: ops@example.com
+//	locked:
+//	  scm: github
+//
+// Defaults are offered as the default answer, locked values are used without prompting
+var (
+	defaultsSection = "defaults"
+	lockedSection   = "locked"
+)
+
+// Config keys for each of the wizard questions
+var (
+	orchestrationKey        = "orchestration"
+	rootDomainKey           = "root_domain"
+	registryKey             = "registry"
+	registryCredentialsKey  = "registry_credentials"
+	registryUsernameKey     = "registry_username"
+	registryPasswordKey     = "registry_password"
+	ecrAccountIDKey         = "ecr_account_id"
+	ecrRegionKey            = "ecr_region"
+	ecrCredentialsFileKey   = "ecr_credentials_file"
+	scmKey                  = "scm"
+	enableOAuthKey          = "enable_oauth"
+	githubAppIDKey          = "github_app_id"
+	githubWebhookSecretKey  = "github_webhook_secret"
+	githubPrivateKeyKey     = "github_private_key_file"
+	githubOrgKey            = "github_org"
+	gitlabWebhookSecretKey  = "gitlab_webhook_secret"
+	gitlabInstanceKey       = "gitlab_instance"
+	oauthClientIDKey        = "oauth_client_id"
+	oauthProviderBaseURLKey = "oauth_provider_base_url"
+	customStorageKey        = "custom_storage"
+	s3URLKey                = "s3_url"
+	s3RegionKey             = "s3_region"
+	s3BucketKey             = "s3_bucket"
+	s3TLSKey                = "s3_tls"
+	dnsProviderKey          = "dns_provider"
+	dnsCredentialsFileKey   = "dns_credentials_file"
+	tlsKey                  = "tls"
+	tlsEmailKey             = "tls_email"
+	tlsIssuerTypeKey        = "tls_issuer_type"
+	gcpProjectIDKey         = "gcp_project_id"
+	awsRegionKey            = "aws_region"
+	awsAccessKeyIDKey       = "aws_access_key_id"
+	auditToKey              = "audit_to"
+	auditURLKey             = "audit_url"
+	customersURLKey         = "customers_url"
+	customersSourceKey      = "customers_source"
+	customersFileKey        = "customers_file"
+	enableDockerfileKey     = "enable_dockerfile_lang"
+	scaleToZeroKey          = "scale_to_zero"
+	ofcVersionKey           = "openfaas_cloud_version"
+	networkPoliciesKey      = "network_policies"
+	ingressKey              = "ingress"
+	buildBranchKey          = "build_branch"
+	customTemplatesKey      = "custom_templates"
+	payloadSecretKey        = "payload_secret"
+)
+
+// answerKeys lists every key that can be answered from outside the wizard
+var answerKeys = []string{
+	orchestrationKey, rootDomainKey, registryKey, scmKey, enableOAuthKey,
+	registryCredentialsKey, registryUsernameKey, registryPasswordKey,
+	ecrAccountIDKey, ecrRegionKey, ecrCredentialsFileKey,
+	githubAppIDKey, githubWebhookSecretKey, githubPrivateKeyKey, githubOrgKey,
+	gitlabWebhookSecretKey, gitlabInstanceKey,
+	oauthClientIDKey, oauthProviderBaseURLKey,
+	customStorageKey, s3URLKey, s3RegionKey, s3BucketKey, s3TLSKey,
+	dnsProviderKey, dnsCredentialsFileKey,
+	tlsKey, tlsEmailKey, tlsIssuerTypeKey, gcpProjectIDKey, awsRegionKey, awsAccessKeyIDKey,
+	auditToKey, auditURLKey, customersURLKey, customersSourceKey, customersFileKey,
+	enableDockerfileKey, scaleToZeroKey,
+	ofcVersionKey, networkPoliciesKey, ingressKey, buildBranchKey, customTemplatesKey,
+	payloadSecretKey,
+}
+
+// configValue returns the value for the key from the given section of the config file,
+// converted to the type of answer the prompt would produce
+func configValue(section string, key string, prompt survey.Prompt) (interface{}, bool) {
+	path := section + "." + key
+	if !viper.IsSet(path) {
+		return nil, false
+	}
+
+	switch prompt.(type) {
+	case *survey.Confirm:
+		return viper.GetBool(path), true
+	case *survey.MultiSelect:
+		return viper.GetStringSlice(path), true
+	default:
+		// lists are answered as comma separated values
+		if _, ok := viper.Get(path).([]interface{}); ok {
+			return strings.Join(viper.GetStringSlice(path), ", "), true
+		}
+		return viper.GetString(path), true
+	}
+}
+
+// setPromptDefault sets the default answer of the prompt to the given value
+func setPromptDefault(prompt survey.Prompt, value interface{}) {
+	switch p := prompt.(type) {
+	case *survey.Input:
+		p.Default = fmt.Sprint(value)
+	case *survey.Select:
+		p.Default = fmt.Sprint(value)
+	case *survey.Confirm:
+		if b, ok := value.(bool); ok {
+			p.Default = b
+		}
+	case *survey.MultiSelect:
+		if s, ok := value.([]string); ok {
+			p.Default = s
+		}
+	}
+}
+
+// presetAnswer returns the value to use for the question without prompting, from a flag,
+// environment variable or locked config value, validated against the question's validator.
+// The source is empty when the question should be asked
+func presetAnswer(key string, prompt survey.Prompt, validate survey.Validator) (interface{}, string, error) {
+	var value interface{}
+	var source string
+
+	if raw, ok := answerOverrides[key]; ok {
+		value, source = raw, sourceFlag
+	} else if raw, ok := os.LookupEnv(envName(key)); ok {
+		value, source = raw, sourceEnv+" "+envName(key)
+	} else if locked, ok := configValue(lockedSection, key, prompt); ok {
+		value, source = locked, sourceLocked
+	} else {
+		return nil, "", nil
+	}
+
+	if raw, ok := value.(string); ok {
+		parsed, err := parseAnswer(prompt, raw)
+		if err != nil {
+			return nil, source, fmt.Errorf("value for %s is invalid: %s", key, err.Error())
+		}
+		value = parsed
+	}
+
+	if err := checkOptions(prompt, value); err != nil {
+		return nil, source, fmt.Errorf("value for %s is invalid: %s", key, err.Error())
+	}
+
+	if validate != nil {
+		if err := validate(value); err != nil {
+			return nil, source, fmt.Errorf("value for %s is invalid: %s", key, err.Error())
+		}
+	}
+	return value, source, nil
+}
+
+// checkOptions returns an error when the value is not one of the options of a select prompt
+func checkOptions(prompt survey.Prompt, value interface{}) error {
+	var options, values []string
+	switch p := prompt.(type) {
+	case *survey.Select:
+		options, values = p.Options, []string{fmt.Sprint(value)}
+	case *survey.MultiSelect:
+		options = p.Options
+		values, _ = value.([]string)
+	default:
+		return nil
+	}
+
+	for _, v := range values {
+		if !contains(options, v) {
+			return fmt.Errorf("%q is not one of %s", v, strings.Join(options, ", "))
+		}
+	}
+	return nil
+}
+
+// isPreset reports whether the key will be answered without prompting
+func isPreset(key string) bool {
+	if _, ok := overrideValue(key); ok {
+		return true
+	}
+	return viper.IsSet(lockedSection + "." + key)
+}
+
+// defaultAnswer returns the value to offer as the default answer for the question, from the
+// config file or an existing init.yml, along with where it came from
+func defaultAnswer(key string, prompt survey.Prompt) (interface{}, string, bool) {
+	if value, ok := configValue(defaultsSection, key, prompt); ok {
+		return value, sourceConfig, true
+	}
+	if value, ok := existingValue(key); ok {
+		return value, sourceExisting, true
+	}
+	return nil, "", false
+}
+
+// promptedSource returns the source to record for a prompted answer, which notes when
+// the offered default was accepted
+func promptedSource(answer interface{}, offered interface{}, offeredSource string) string {
+	if offeredSource != "" && fmt.Sprint(answer) == fmt.Sprint(offered) {
+		return sourcePrompt + ", accepted " + offeredSource
+	}
+	return sourcePrompt
+}
+
+// askOne asks a single question identified by the config key, unless its value is locked.
+// It exits when a preset value is invalid
+func askOne(key string, prompt survey.Prompt, response interface{}, validate survey.Validator) error {
+	// an invalid flag, environment or locked value can not be corrected by prompting
+	value, source, err := presetAnswer(key, prompt, validate)
+	if err != nil {
+		exitWithError(err)
+	}
+	if source != "" {
+		recordSource(key, source)
+		return core.WriteAnswer(response, "", value)
+	}
+
+	offered, offeredSource, ok := defaultAnswer(key, prompt)
+	if ok {
+		setPromptDefault(prompt, offered)
+	}
+	if err := survey.AskOne(prompt, response, validate); err != nil {
+		return err
+	}
+
+	recordSource(key, promptedSource(reflect.ValueOf(response).Elem().Interface(), offered, offeredSource))
+	return nil
+}
+
+// ask asks each of the questions that are not locked, using the keys to map the question
+// names to their config keys. Locked values are written directly into the response, exiting
+// when one is invalid
+func ask(questions []*survey.Question, keys map[string]string, response interface{}) error {
+	remaining := []*survey.Question{}
+	offered := map[string]interface{}{}
+	offeredSources := map[string]string{}
+
+	for _, q := range questions {
+		key, ok := keys[q.Name]
+		if !ok {
+			remaining = append(remaining, q)
+			continue
+		}
+
+		value, source, err := presetAnswer(key, q.Prompt, q.Validate)
+		if err != nil {
+			exitWithError(err)
+		}
+		if source != "" {
+			recordSource(key, source)
+			if err := core.WriteAnswer(response, q.Name, value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if value, source, ok := defaultAnswer(key, q.Prompt); ok {
+			setPromptDefault(q.Prompt, value)
+			offered[q.Name] = value
+			offeredSources[q.Name] = source
+		}
+		remaining = append(remaining, q)
+	}
+
+	if err := survey.Ask(remaining, response); err != nil {
+		return err
+	}
+
+	answers := reflect.ValueOf(response).Elem()
+	for _, q := range remaining {
+		if key, ok := keys[q.Name]; ok {
+			answer := answers.FieldByName(q.Name).Interface()
+			recordSource(key, promptedSource(answer, offered[q.Name], offeredSources[q.Name]))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"testing"
+
+	"gopkg.in/AlecAivazis/survey.v1"
+)
+
+func Test_presetAnswer_RejectsUnknownOption(t *testing.T) {
+	answerOverrides[scmKey] = "githb"
+	defer delete(answerOverrides, scmKey)
+
+	prompt := &survey.Select{Options: []string{github, gitlab}}
+	if _, _, err := presetAnswer(scmKey, prompt, survey.Required); err == nil {
+		t.Fatal("want an error for a value which is not an option")
+	}
+}
+
+func Test_presetAnswer_AcceptsOption(t *testing.T) {
+	answerOverrides[scmKey] = gitlab
+	defer delete(answerOverrides, scmKey)
+
+	prompt := &survey.Select{Options: []string{github, gitlab}}
+	value, source, err := presetAnswer(scmKey, prompt, survey.Required)
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+	if value != gitlab || source != sourceFlag {
+		t.Errorf("want %q from %q, got %q from %q", gitlab, sourceFlag, value, source)
+	}
+}
+
+func Test_presetAnswer_InvalidBool(t *testing.T) {
+	answerOverrides[enableOAuthKey] = "maybe"
+	defer delete(answerOverrides, enableOAuthKey)
+
+	if _, _, err := presetAnswer(enableOAuthKey, &survey.Confirm{}, nil); err == nil {
+		t.Fatal("want an error for a value which is not a bool")
+	}
+}
+
+func Test_checkOptions_MultiSelect(t *testing.T) {
+	prompt := &survey.MultiSelect{Options: []string{"a", "b"}}
+
+	if err := checkOptions(prompt, []string{"a", "b"}); err != nil {
+		t.Errorf("want no error, got %s", err)
+	}
+	if err := checkOptions(prompt, []string{"a", "c"}); err == nil {
+		t.Error("want an error for c")
+	}
+}
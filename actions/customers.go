@@ -0,0 +1,307 @@
+package actions
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/burtonr/ofc-wizard/types"
+	homedir "github.com/mitchellh/go-homedir"
+	"gopkg.in/AlecAivazis/survey.v1"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	customersSecretName  = "customers"
+	customersFilter      = "customers"
+	defaultCustomersFile = "customers"
+)
+
+// Where the customers list comes from
+var (
+	customersFromURL    = "url"
+	customersFromSecret = "secret"
+)
+
+var gitlabNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.][A-Za-z0-9_.-]*[A-Za-z0-9_-]$|^[A-Za-z0-9_]$`)
+
+// validateCustomer returns an error when the name is not a valid user or organisation name
+// for the source control management
+func validateCustomer(scm string, name string) error {
+	switch scm {
+	case gitlab:
+		if len(name) > 255 || !gitlabNamePattern.MatchString(name) || strings.HasSuffix(name, ".git") || strings.HasSuffix(name, ".atom") {
+			return fmt.Errorf("%q is not a valid GitLab user or group name", name)
+		}
+	default:
+		if len(name) > 39 || !githubNamePattern.MatchString(name) {
+			return fmt.Errorf("%q is not a valid Github user or organisation name", name)
+		}
+	}
+	return nil
+}
+
+// parseCustomers returns the names in the customers list, ignoring blank lines and # comments,
+// along with an error for each invalid or repeated name
+func parseCustomers(scm string, data []byte) ([]string, []error) {
+	names := []string{}
+	errs := []error{}
+	seen := map[string]bool{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		name := strings.TrimSpace(scanner.Text())
+		if name == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+
+		if err := validateCustomer(scm, name); err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %s", line, err.Error()))
+			continue
+		}
+
+		// names are not case sensitive
+		if seen[strings.ToLower(name)] {
+			errs = append(errs, fmt.Errorf("line %d: %s is listed more than once", line, name))
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+	return names, errs
+}
+
+// customersPath returns the local path of a customers list given as a path or a file:// URL
+func customersPath(location string) (string, error) {
+	if !strings.Contains(location, "://") {
+		return homedir.Expand(location)
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("%s is not a local file, use a path or a file:// URL", location)
+	}
+	return u.Path, nil
+}
+
+// checkCustomersFile returns the names in the customers list at the location, and an error for
+// each problem with it
+func checkCustomersFile(scm string, location string) ([]string, []error) {
+	path, err := customersPath(location)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	names, errs := parseCustomers(scm, data)
+	if len(names) == 0 && len(errs) == 0 {
+		errs = append(errs, fmt.Errorf("%s does not list any users or organisations", location))
+	}
+	return names, errs
+}
+
+// validateCustomersURL is a survey validator for the customers URL, which must be public
+func validateCustomersURL(val interface{}) error {
+	str, ok := val.(string)
+	if !ok {
+		return errors.New("The customers URL must be a string")
+	}
+	if str == "" {
+		return nil
+	}
+
+	u, err := url.Parse(str)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return errors.New("The customers URL must be a public http or https URL, a local list can be used as a customers secret instead")
+	}
+	return nil
+}
+
+// customersSecret returns the secret which reads the customers list from the file
+func customersSecret(path string) types.Secret {
+	return types.Secret{
+		Name:      customersSecretName,
+		Files:     []types.FileValue{{Name: customersSecretName, ValueFrom: path}},
+		Filters:   []string{customersFilter},
+		Namespace: defaultNamespace,
+	}
+}
+
+// customersSource returns how the customers list of the init.yml is provided
+func customersSource(yml types.InitYaml) string {
+	if yml.CustomersSecret {
+		return customersFromSecret
+	}
+	return customersFromURL
+}
+
+// customersFile returns the file the customers secret of the init.yml reads from
+func customersFile(yml types.InitYaml) string {
+	i := findSecret(yml.Secrets, customersSecretName)
+	if i < 0 || len(yml.Secrets[i].Files) == 0 {
+		return ""
+	}
+	return yml.Secrets[i].Files[0].ValueFrom
+}
+
+// validateCustomersFile returns a survey validator for the customers file, checking the names
+// are valid for the source control management. A missing file is created by the wizard
+func validateCustomersFile(scm string) survey.Validator {
+	return func(val interface{}) error {
+		str, ok := val.(string)
+		if !ok {
+			return errors.New("The customers file must be a string")
+		}
+		if path, err := customersPath(str); err == nil {
+			if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+				return nil
+			}
+		}
+		if _, errs := checkCustomersFile(scm, str); len(errs) > 0 {
+			return errs[0]
+		}
+		return nil
+	}
+}
+
+// writeCustomers writes the valid names to the customers list at the output path
+func writeCustomers(scm string, names []string, output string) ([]string, []error) {
+	valid, errs := parseCustomers(scm, []byte(strings.Join(names, "\n")))
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(valid) == 0 {
+		return nil, []error{errors.New("give at least one user or organisation")}
+	}
+
+	path, err := customersPath(output)
+	if err != nil {
+		return nil, []error{err}
+	}
+	if err := ioutil.WriteFile(path, []byte(strings.Join(valid, "\n")+"\n"), 0644); err != nil {
+		return nil, []error{err}
+	}
+	return valid, nil
+}
+
+// askCustomersFile asks for the customers file, creating it from the names given when it does not exist
+func askCustomersFile(scm string) (string, error) {
+	file := defaultCustomersFile
+	var fileQuestion = &survey.Input{
+		Message: "Enter the path of the customers file:",
+		Default: defaultCustomersFile,
+		Help:    "A file listing one user or organisation per line. It can be created with 'ofc-wizard customers create'",
+	}
+	if err := askOne(customersFileKey, fileQuestion, &file, validateCustomersFile(scm)); err != nil {
+		return "", err
+	}
+
+	path, err := customersPath(file)
+	if err != nil {
+		return file, nil
+	}
+	if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+		return file, nil
+	}
+
+	for {
+		names := ""
+		var namesQuestion = &survey.Input{
+			Message: fmt.Sprintf("%s does not exist, enter the users or organisations allowed to deploy (comma separated):", file),
+		}
+		if err := survey.AskOne(namesQuestion, &names, survey.Required); err != nil {
+			return "", err
+		}
+
+		valid, errs := writeCustomers(scm, splitList(names), file)
+		if len(errs) == 0 {
+			fmt.Printf("Wrote %d customers to %s\n", len(valid), file)
+			return file, nil
+		}
+		for _, e := range errs {
+			fmt.Println(e.Error())
+		}
+	}
+}
+
+// checkCustomersConfig returns an error for each problem with the customers settings of the init.yml
+func checkCustomersConfig(yml types.InitYaml) []error {
+	errs := []error{}
+	if !yml.CustomersSecret {
+		if err := validateCustomersURL(yml.CustomersURL); err != nil {
+			errs = append(errs, fmt.Errorf("customers_url: %s", err.Error()))
+		}
+		return errs
+	}
+
+	if yml.CustomersURL != "" {
+		errs = append(errs, errors.New("customers_url is not used when customers_secret is enabled, remove it"))
+	}
+	if findSecret(yml.Secrets, customersSecretName) < 0 {
+		errs = append(errs, fmt.Errorf("customers_secret is enabled but there is no %s secret", customersSecretName))
+	}
+	return errs
+}
+
+// customersSCM returns the source control management of the init.yml at the path, or Github
+// when it can not be read
+func customersSCM(path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return github
+	}
+
+	yml := types.InitYaml{}
+	if yamlErr := yaml.Unmarshal(data, &yml); yamlErr != nil || yml.SCM == "" {
+		return github
+	}
+	return yml.SCM
+}
+
+// CreateCustomers writes the customers list with the names to the output file, after checking
+// the names are valid for the source control management, which is read from the init.yml
+// at the path when it is not given
+func CreateCustomers(path string, scm string, names []string, output string) {
+	if scm == "" {
+		scm = customersSCM(path)
+	}
+
+	valid, errs := writeCustomers(scm, names, output)
+	if len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Println(e.Error())
+		}
+		exitWithError(errors.New("the customers list was not written"))
+	}
+	fmt.Printf("Wrote %d customers to %s\n", len(valid), output)
+}
+
+// CheckCustomers checks the customers list at the location, a path or file:// URL, exiting
+// with an error when it is invalid
+func CheckCustomers(path string, scm string, location string) {
+	if scm == "" {
+		scm = customersSCM(path)
+	}
+
+	names, errs := checkCustomersFile(scm, location)
+	if len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Println(e.Error())
+		}
+		exitWithError(fmt.Errorf("%s is not a valid %s customers list", location, scm))
+	}
+	fmt.Printf("%s is valid, listing %d customers\n", location, len(names))
+}
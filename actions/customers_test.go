@@ -0,0 +1,108 @@
+package actions
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func Test_validateCustomer(t *testing.T) {
+	cases := []struct {
+		scm   string
+		name  string
+		valid bool
+	}{
+		{github, "openfaas", true},
+		{github, "alexellis2", true},
+		{github, "open-faas", true},
+		{github, "-openfaas", false},
+		{github, "open--faas", false},
+		{github, "open_faas", false},
+		{github, strings.Repeat("a", 40), false},
+		{gitlab, "open_faas", true},
+		{gitlab, "open.faas", true},
+		{gitlab, "a", true},
+		{gitlab, "-openfaas", false},
+		{gitlab, "openfaas.", false},
+		{gitlab, "openfaas.git", false},
+		{gitlab, "openfaas.atom", false},
+	}
+
+	for _, c := range cases {
+		if err := validateCustomer(c.scm, c.name); (err == nil) != c.valid {
+			t.Errorf("%s %q: want valid %t, got %v", c.scm, c.name, c.valid, err)
+		}
+	}
+}
+
+func Test_parseCustomers(t *testing.T) {
+	data := []byte("# customers\nopenfaas\n\n  alexellis  \nOpenFaaS\nnot valid\n")
+
+	names, errs := parseCustomers(github, data)
+	if want := []string{"openfaas", "alexellis"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("want %v, got %v", want, names)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("want errors for the repeated and invalid names, got %v", errs)
+	}
+	if !strings.HasPrefix(errs[0].Error(), "line 5:") || !strings.HasPrefix(errs[1].Error(), "line 6:") {
+		t.Errorf("want the line numbers of the errors, got %v", errs)
+	}
+}
+
+func Test_writeCustomers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ofc-wizard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, "customers")
+	if _, errs := writeCustomers(github, []string{"openfaas", "alexellis"}, output); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	names, errs := checkCustomersFile(github, "file://"+output)
+	if len(errs) > 0 || !reflect.DeepEqual(names, []string{"openfaas", "alexellis"}) {
+		t.Errorf("want the names written, got %v %v", names, errs)
+	}
+
+	if _, errs := writeCustomers(github, []string{"not valid"}, output); len(errs) == 0 {
+		t.Error("want an error for an invalid name")
+	}
+	if _, errs := writeCustomers(github, []string{}, output); len(errs) == 0 {
+		t.Error("want an error when there are no names")
+	}
+}
+
+func Test_customersPath(t *testing.T) {
+	if path, err := customersPath("file:///tmp/customers"); err != nil || path != "/tmp/customers" {
+		t.Errorf("want /tmp/customers, got %s %v", path, err)
+	}
+	if _, err := customersPath("https://example.com/customers"); err == nil {
+		t.Error("want an error for a remote URL")
+	}
+}
+
+func Test_checkCustomersConfig(t *testing.T) {
+	yml := types.InitYaml{CustomersURL: "https://example.com/customers"}
+	if errs := checkCustomersConfig(yml); len(errs) != 0 {
+		t.Errorf("want no errors for a customers URL, got %v", errs)
+	}
+
+	yml.CustomersSecret = true
+	if errs := checkCustomersConfig(yml); len(errs) != 2 {
+		t.Errorf("want errors for the URL and the missing secret, got %v", errs)
+	}
+
+	yml.CustomersURL = ""
+	yml.Secrets = []types.Secret{customersSecret("customers")}
+	if errs := checkCustomersConfig(yml); len(errs) != 0 {
+		t.Errorf("want no errors for the customers secret, got %v", errs)
+	}
+}
@@ -0,0 +1,172 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Kinds of change between two values of an init.yml field
+var (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeChanged = "changed"
+)
+
+// fieldChange is a difference in the value of a single init.yml field, or of a whole
+// named list item such as a secret
+type fieldChange struct {
+	Path   string `json:"path"`
+	Kind   string `json:"kind"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// DiffInitFiles prints the field level changes between two init.yml files, as text or json
+func DiffInitFiles(fromPath string, toPath string, output string) {
+	before, err := flattenYaml(*LoadInitFileFrom(fromPath))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "-%s gave error: %s\n", fromPath, err.Error())
+		os.Exit(1)
+	}
+
+	after, err := flattenYaml(*LoadInitFileFrom(toPath))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "-%s gave error: %s\n", toPath, err.Error())
+		os.Exit(1)
+	}
+
+	changes := diffFields(before, after)
+
+	switch output {
+	case "json":
+		out, err := json.MarshalIndent(redactChanges(changes), "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+	case "text", "":
+		printChanges(changes)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown output format %q, expected text or json\n", output)
+		os.Exit(1)
+	}
+}
+
+// diffFields compares the flattened values of two init.yml documents, in the order the
+// fields appear in the documents. Named list items which only appear in one of the
+// documents are reported as a single change
+func diffFields(before []fieldValue, after []fieldValue) []fieldChange {
+	beforeValues, beforeItems := indexFields(before)
+	afterValues, afterItems := indexFields(after)
+
+	changes := []fieldChange{}
+	reported := map[string]bool{}
+
+	for _, f := range before {
+		if item := missingItem(f.Path, afterItems); item != "" {
+			if !reported[item] {
+				changes = append(changes, fieldChange{Path: item, Kind: changeRemoved})
+				reported[item] = true
+			}
+			continue
+		}
+
+		v, ok := afterValues[f.Path]
+		if !ok {
+			changes = append(changes, fieldChange{Path: f.Path, Kind: changeRemoved, Before: f.Value})
+		} else if v != f.Value {
+			changes = append(changes, fieldChange{Path: f.Path, Kind: changeChanged, Before: f.Value, After: v})
+		}
+	}
+
+	for _, f := range after {
+		if item := missingItem(f.Path, beforeItems); item != "" {
+			if !reported[item] {
+				changes = append(changes, fieldChange{Path: item, Kind: changeAdded})
+				reported[item] = true
+			}
+			continue
+		}
+
+		if _, ok := beforeValues[f.Path]; !ok {
+			changes = append(changes, fieldChange{Path: f.Path, Kind: changeAdded, After: f.Value})
+		}
+	}
+	return changes
+}
+
+// redactChanges hides the values of the changes to secret paths
+func redactChanges(changes []fieldChange) []fieldChange {
+	redacted := []fieldChange{}
+	for _, c := range changes {
+		c.Before = redact(c.Path, c.Before)
+		c.After = redact(c.Path, c.After)
+		redacted = append(redacted, c)
+	}
+	return redacted
+}
+
+// indexFields returns the value of each path, and the set of list items the paths belong to
+func indexFields(fields []fieldValue) (map[string]string, map[string]bool) {
+	values := map[string]string{}
+	items := map[string]bool{}
+
+	for _, f := range fields {
+		values[f.Path] = f.Value
+		for _, item := range itemPaths(f.Path) {
+			items[item] = true
+		}
+	}
+	return values, items
+}
+
+// itemPaths returns the path of each list item the path is nested in, outermost first,
+// eg: secrets[s3] and secrets[s3].literals[s3-access-key]
+func itemPaths(path string) []string {
+	paths := []string{}
+	for i, c := range path {
+		if c == ']' {
+			paths = append(paths, path[:i+1])
+		}
+	}
+	return paths
+}
+
+// missingItem returns the outermost list item of the path which is not in the items
+func missingItem(path string, items map[string]bool) string {
+	for _, item := range itemPaths(path) {
+		if !items[item] {
+			return item
+		}
+	}
+	return ""
+}
+
+// printChanges prints the changes as a unified list, with secret values redacted
+func printChanges(changes []fieldChange) {
+	if len(changes) == 0 {
+		fmt.Println("No changes")
+		return
+	}
+
+	for _, c := range changes {
+		switch c.Kind {
+		case changeAdded:
+			printChange("+", c.Path, c.After)
+		case changeRemoved:
+			printChange("-", c.Path, c.Before)
+		default:
+			fmt.Printf("~ %s: %s -> %s\n", c.Path, redact(c.Path, c.Before), redact(c.Path, c.After))
+		}
+	}
+}
+
+func printChange(prefix string, path string, value string) {
+	if value == "" {
+		fmt.Printf("%s %s\n", prefix, path)
+		return
+	}
+	fmt.Printf("%s %s: %s\n", prefix, path, redact(path, value))
+}
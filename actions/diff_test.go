@@ -0,0 +1,82 @@
+package actions
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func Test_diffFields(t *testing.T) {
+	before := []fieldValue{
+		{Path: "root_domain", Value: "example.com"},
+		{Path: "tls", Value: "false"},
+		{Path: "secrets[s3].literals[s3-access-key].name", Value: "s3-access-key"},
+		{Path: "secrets[s3].literals[s3-access-key].value", Value: "old"},
+		{Path: "secrets[registry-secret].files[config.json].name", Value: "config.json"},
+		{Path: "secrets[registry-secret].files[config.json].value_from", Value: "~/.docker/config.json"},
+	}
+	after := []fieldValue{
+		{Path: "root_domain", Value: "example.com"},
+		{Path: "tls", Value: "true"},
+		{Path: "secrets[s3].literals[s3-access-key].name", Value: "s3-access-key"},
+		{Path: "secrets[s3].literals[s3-access-key].value", Value: "new"},
+		{Path: "secrets[s3].literals[s3-secret-key].name", Value: "s3-secret-key"},
+		{Path: "secrets[s3].literals[s3-secret-key].value", Value: "secret"},
+		{Path: "slack.url", Value: "http://echo"},
+	}
+
+	want := []fieldChange{
+		{Path: "tls", Kind: changeChanged, Before: "false", After: "true"},
+		{Path: "secrets[s3].literals[s3-access-key].value", Kind: changeChanged, Before: "old", After: "new"},
+		{Path: "secrets[registry-secret]", Kind: changeRemoved},
+		{Path: "secrets[s3].literals[s3-secret-key]", Kind: changeAdded},
+		{Path: "slack.url", Kind: changeAdded, After: "http://echo"},
+	}
+
+	if got := diffFields(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func Test_diffFields_InitYaml(t *testing.T) {
+	before, err := flattenYaml(types.InitYaml{
+		Secrets: []types.Secret{
+			{Name: "payload-secret", Literals: []types.Literal{{Name: "payload-secret", Value: "old"}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := flattenYaml(types.InitYaml{
+		Secrets: []types.Secret{
+			{Name: "payload-secret", Literals: []types.Literal{{Name: "payload-secret", Value: "new"}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := redactChanges(diffFields(before, after))
+	want := []fieldChange{
+		{Path: "secrets[payload-secret].literals[payload-secret].value", Kind: changeChanged, Before: "********", After: "********"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("want %v, got %v", want, changes)
+	}
+}
+
+func Test_diffFields_NoChanges(t *testing.T) {
+	fields := []fieldValue{{Path: "root_domain", Value: "example.com"}}
+	if got := diffFields(fields, fields); len(got) != 0 {
+		t.Errorf("want no changes, got %v", got)
+	}
+}
+
+func Test_itemPaths(t *testing.T) {
+	want := []string{"secrets[s3]", "secrets[s3].literals[s3-access-key]"}
+	if got := itemPaths("secrets[s3].literals[s3-access-key].value"); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,198 @@
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+var (
+	bindFormat = "bind"
+	jsonFormat = "json"
+	textFormat = "text"
+	dnsTTL     = 300
+)
+
+var domainLabelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// secondLevelSuffixes are common public suffixes of two labels, used to tell an apex domain
+// such as example.co.uk from a subdomain
+var secondLevelSuffixes = []string{
+	"co.uk", "org.uk", "ac.uk", "gov.uk", "com.au", "net.au", "org.au",
+	"co.nz", "co.jp", "co.za", "com.br", "com.cn", "com.mx", "co.in",
+}
+
+// dnsRecord is a DNS record which must be created for OpenFaaS Cloud
+type dnsRecord struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Value   string `json:"value"`
+	TTL     int    `json:"ttl"`
+	Purpose string `json:"purpose"`
+}
+
+// validateRootDomain is a survey validator for the root domain, which must be an RFC 1123 host name
+func validateRootDomain(val interface{}) error {
+	str, ok := val.(string)
+	if !ok {
+		return errors.New("The root domain must be a string")
+	}
+	return checkRootDomain(normaliseDomain(str))
+}
+
+// normaliseDomain lower cases the domain and removes the trailing dot of a fully qualified name
+func normaliseDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+func checkRootDomain(domain string) error {
+	if domain == "" {
+		return errors.New("The root domain is required")
+	}
+	if strings.Contains(domain, "://") || strings.Contains(domain, "/") {
+		return errors.New("The root domain must be a host name, without a scheme or path (eg: faas.example.com)")
+	}
+	if len(domain) > 253 {
+		return errors.New("The root domain must be 253 characters or fewer")
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return errors.New("The root domain must include a top level domain (eg: faas.example.com)")
+	}
+
+	for _, l := range labels {
+		if len(l) > 63 {
+			return fmt.Errorf("%q is longer than 63 characters", l)
+		}
+		if !domainLabelPattern.MatchString(l) {
+			return fmt.Errorf("%q is not a valid part of a domain, use letters, digits and hyphens, not starting or ending with a hyphen", l)
+		}
+	}
+	return nil
+}
+
+// isApexDomain reports whether the domain is registered directly under a public suffix,
+// such as example.com, rather than a subdomain such as faas.example.com
+func isApexDomain(domain string) bool {
+	labels := strings.Split(domain, ".")
+	if len(labels) <= 2 {
+		return true
+	}
+
+	suffix := strings.Join(labels[len(labels)-2:], ".")
+	return len(labels) == 3 && contains(secondLevelSuffixes, suffix)
+}
+
+// apexDomainWarning returns a warning when the root domain is an apex domain
+func apexDomainWarning(domain string) string {
+	if !isApexDomain(domain) {
+		return ""
+	}
+	return fmt.Sprintf("%s is an apex domain, the wildcard record *.%s will send every other subdomain to OpenFaaS Cloud. Consider a subdomain such as faas.%s", domain, domain, domain)
+}
+
+// dnsRecords returns the records to create for the init.yml, pointing to the address.
+// When the address is empty a placeholder describing it is used
+func dnsRecords(yml types.InitYaml, address string) []dnsRecord {
+	recordType := "A"
+	switch ip := net.ParseIP(address); {
+	case address == "":
+		address = ingressAddress(yml)
+	case ip == nil:
+		recordType = "CNAME"
+		address = strings.TrimSuffix(address, ".") + "."
+	case ip.To4() == nil:
+		recordType = "AAAA"
+	}
+
+	records := []dnsRecord{
+		{Name: "*." + yml.RootDomain, Purpose: fmt.Sprintf("user functions, eg: %s://<user>.%s", endpointScheme(yml), yml.RootDomain)},
+		{Name: "system." + yml.RootDomain, Purpose: "dashboard and system functions, " + systemURL(yml, "")},
+	}
+	if yml.EnableOAuth {
+		records = append(records, dnsRecord{Name: "auth.system." + yml.RootDomain, Purpose: "OAuth login, " + authURL(yml)})
+	}
+
+	for i := range records {
+		records[i].Type = recordType
+		records[i].Value = address
+		records[i].TTL = dnsTTL
+	}
+	return records
+}
+
+// ingressAddress describes the address the records must point to for the ingress of the init.yml
+func ingressAddress(yml types.InitYaml) string {
+	switch {
+	case yml.Orchestration == swarm:
+		return "<ip of a swarm manager>"
+	case yml.Ingress == "host":
+		return "<public ip of the node running the ingress controller>"
+	}
+	return "<external ip of the ingress controller load balancer>"
+}
+
+// writeDNSPlan writes the DNS records in the given format
+func writeDNSPlan(yml types.InitYaml, records []dnsRecord, format string, out io.Writer) error {
+	switch format {
+	case textFormat:
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tTYPE\tVALUE\tPURPOSE")
+		for _, r := range records {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Name, r.Type, r.Value, r.Purpose)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		if yml.TLS {
+			fmt.Fprintf(out, "\ncert-manager creates the _acme-challenge TXT records with %s to issue the certificates\n", dnsFriendlyName(yml.TLSConfig.DNSService))
+		}
+		return nil
+	case bindFormat:
+		fmt.Fprintf(out, "; DNS records for OpenFaaS Cloud at %s, generated by ofc-wizard\n", yml.RootDomain)
+		for _, r := range records {
+			fmt.Fprintf(out, "; %s\n%s.\t%d\tIN\t%s\t%s\n", r.Purpose, r.Name, r.TTL, r.Type, r.Value)
+		}
+		return nil
+	case jsonFormat:
+		enc := json.NewEncoder(out)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+	return fmt.Errorf("Unknown format %q, expected %s, %s or %s", format, textFormat, bindFormat, jsonFormat)
+}
+
+// printDNSPlan prints the DNS records to create after generating the init.yml
+func printDNSPlan(yml types.InitYaml) {
+	fmt.Printf("\nCreate these DNS records for %s:\n\n", yml.RootDomain)
+	writeDNSPlan(yml, dnsRecords(yml, ""), textFormat, os.Stdout)
+	fmt.Println("\nRun 'ofc-wizard export dns --format bind --address <ip>' to export them as a zone file fragment")
+}
+
+// ExportDNS writes the DNS records needed by the init.yml at the path in the given format,
+// pointing to the address when one is given
+func ExportDNS(path string, format string, address string, out io.Writer) {
+	yml := LoadInitFileFrom(path)
+
+	if err := checkRootDomain(yml.RootDomain); err != nil {
+		exitWithError(err)
+	}
+	if format == bindFormat && address == "" {
+		fmt.Fprintln(os.Stderr, "Warning: no --address was given, replace the placeholder values before loading the records")
+	}
+
+	if err := writeDNSPlan(*yml, dnsRecords(*yml, address), format, out); err != nil {
+		exitWithError(err)
+	}
+}
@@ -0,0 +1,98 @@
+package actions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func Test_checkRootDomain(t *testing.T) {
+	for _, domain := range []string{"example.com", "faas.example.co.uk", "o6s.io", "a-b.example.com"} {
+		if err := checkRootDomain(domain); err != nil {
+			t.Errorf("%q: want no error, got %s", domain, err)
+		}
+	}
+}
+
+func Test_checkRootDomain_Invalid(t *testing.T) {
+	for _, domain := range []string{
+		"",
+		"localhost",
+		"https://example.com",
+		"example.com/faas",
+		"-faas.example.com",
+		"faas-.example.com",
+		"faas..example.com",
+		"faas_1.example.com",
+		strings.Repeat("a", 64) + ".example.com",
+		strings.Repeat("a.", 127) + "com",
+	} {
+		if err := checkRootDomain(domain); err == nil {
+			t.Errorf("%q: want an error", domain)
+		}
+	}
+}
+
+func Test_validateRootDomain_Normalises(t *testing.T) {
+	if err := validateRootDomain(" Faas.Example.com. "); err != nil {
+		t.Errorf("want no error, got %s", err)
+	}
+}
+
+func Test_isApexDomain(t *testing.T) {
+	cases := map[string]bool{
+		"example.com":         true,
+		"example.co.uk":       true,
+		"faas.example.com":    false,
+		"faas.example.co.uk":  false,
+		"a.b.faas.example.io": false,
+	}
+
+	for domain, want := range cases {
+		if got := isApexDomain(domain); got != want {
+			t.Errorf("%q: want %t, got %t", domain, want, got)
+		}
+	}
+}
+
+func Test_dnsRecords(t *testing.T) {
+	yml := types.InitYaml{RootDomain: "example.com", TLS: true, EnableOAuth: true}
+
+	cases := map[string]string{
+		"":                "A",
+		"203.0.113.10":    "A",
+		"2001:db8::1":     "AAAA",
+		"lb.example.net.": "CNAME",
+	}
+	for address, want := range cases {
+		records := dnsRecords(yml, address)
+		if len(records) != 3 {
+			t.Fatalf("want 3 records with OAuth, got %d", len(records))
+		}
+		if records[0].Type != want {
+			t.Errorf("%q: want %s records, got %s", address, want, records[0].Type)
+		}
+	}
+
+	if records := dnsRecords(types.InitYaml{RootDomain: "example.com"}, ""); len(records) != 2 {
+		t.Errorf("want 2 records without OAuth, got %d", len(records))
+	}
+}
+
+func Test_writeDNSPlan_Bind(t *testing.T) {
+	yml := types.InitYaml{RootDomain: "example.com"}
+	out := &bytes.Buffer{}
+
+	if err := writeDNSPlan(yml, dnsRecords(yml, "203.0.113.10"), bindFormat, out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "*.example.com.\t300\tIN\tA\t203.0.113.10\n") {
+		t.Errorf("want the wildcard record, got:\n%s", out.String())
+	}
+
+	if err := writeDNSPlan(yml, nil, "yaml", out); err == nil {
+		t.Error("want an error for an unknown format")
+	}
+}
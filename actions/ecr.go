@@ -0,0 +1,134 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/burtonr/ofc-wizard/types"
+	"gopkg.in/AlecAivazis/survey.v1"
+)
+
+var (
+	ecrSecretName      = "aws-ecr-credentials"
+	ecrCredentialsFile = "~/.aws/credentials"
+)
+
+// ecrRegions lists the AWS regions with ECR
+var ecrRegions = []string{
+	"us-east-1", "us-east-2", "us-west-1", "us-west-2",
+	"af-south-1", "ap-east-1", "ap-south-1",
+	"ap-northeast-1", "ap-northeast-2", "ap-northeast-3", "ap-southeast-1", "ap-southeast-2",
+	"ca-central-1", "eu-central-1", "eu-north-1", "eu-south-1", "eu-west-1", "eu-west-2", "eu-west-3",
+	"me-south-1", "sa-east-1",
+	"cn-north-1", "cn-northwest-1", "us-gov-east-1", "us-gov-west-1",
+}
+
+var awsAccountPattern = regexp.MustCompile(`^[0-9]{12}$`)
+
+type ecrAnswers struct {
+	Account         string
+	Region          string
+	CredentialsFile string
+}
+
+// askECRQuestions asks for the account, region and AWS credentials used to push to the ECR registry
+func askECRQuestions(ref registryRef) (*ecrAnswers, error) {
+	var questions = []*survey.Question{
+		{
+			Name:     "Account",
+			Prompt:   &survey.Input{Message: "Enter the AWS account ID of the registry:", Default: ref.Account},
+			Validate: validateAWSAccount,
+		},
+		{
+			Name:     "Region",
+			Prompt:   &survey.Input{Message: "Enter the AWS region of the registry:", Default: ref.Region},
+			Validate: validateECRRegion,
+		},
+		{
+			Name: "CredentialsFile",
+			Prompt: &survey.Input{
+				Message: "Enter the path of the AWS credentials file:",
+				Default: ecrCredentialsFile,
+				Help:    "An AWS shared credentials file with the access key of a user allowed to push to ECR, such as with the AmazonEC2ContainerRegistryPowerUser policy",
+			},
+			Validate: survey.Required,
+		},
+	}
+
+	keys := map[string]string{
+		"Account":         ecrAccountIDKey,
+		"Region":          ecrRegionKey,
+		"CredentialsFile": ecrCredentialsFileKey,
+	}
+
+	a := &ecrAnswers{}
+
+	if err := ask(questions, keys, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// ecrSecret returns the secret holding the AWS credentials ofc-bootstrap uses for ECR
+func ecrSecret(credentialsFile string) types.Secret {
+	return types.Secret{
+		Name:      ecrSecretName,
+		Files:     []types.FileValue{{Name: "credentials", ValueFrom: credentialsFile}},
+		Filters:   []string{ecrFilter},
+		Namespace: defaultNamespace,
+	}
+}
+
+func validateAWSAccount(val interface{}) error {
+	if str, ok := val.(string); !ok || !awsAccountPattern.MatchString(str) {
+		return errors.New("The AWS account ID must be 12 digits")
+	}
+	return nil
+}
+
+func validateECRRegion(val interface{}) error {
+	if str, ok := val.(string); !ok || !contains(ecrRegions, str) {
+		return fmt.Errorf("%v is not an AWS region with ECR", val)
+	}
+	return nil
+}
+
+// checkECR returns an error for each ECR setting that does not match the registry
+func checkECR(ref registryRef, account string, region string) []error {
+	errs := []error{}
+
+	if ref.Type != ecrRegistry {
+		return append(errs, fmt.Errorf("ECR is enabled but the registry %s is not an ECR registry", ref.String()))
+	}
+
+	if account != "" && account != ref.Account {
+		errs = append(errs, fmt.Errorf("the registry belongs to the AWS account %s, not %s", ref.Account, account))
+	}
+
+	if err := validateECRRegion(region); err != nil {
+		errs = append(errs, err)
+	} else if region != ref.Region {
+		errs = append(errs, fmt.Errorf("the registry is in the region %s, but ECR is configured for %s", ref.Region, region))
+	}
+
+	// China regions are only reached through amazonaws.com.cn
+	if strings.HasPrefix(ref.Region, "cn-") != strings.HasSuffix(ref.Host, ".cn") {
+		errs = append(errs, fmt.Errorf("the registry host %s does not match the partition of the region %s", ref.Host, ref.Region))
+	}
+	return errs
+}
+
+// checkECRConfig returns an error for each problem with the ECR settings of the init.yml
+func checkECRConfig(yml types.InitYaml) []error {
+	if !yml.EnableECR {
+		return nil
+	}
+
+	ref, err := parseRegistry(yml.Registry)
+	if err != nil {
+		return nil
+	}
+	return checkECR(ref, "", yml.ECRConfig.ECRRegion)
+}
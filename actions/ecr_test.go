@@ -0,0 +1,54 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func Test_checkECR(t *testing.T) {
+	cases := []struct {
+		name     string
+		registry string
+		account  string
+		region   string
+		want     int
+	}{
+		{"matching", "123456789012.dkr.ecr.eu-west-1.amazonaws.com/", "123456789012", "eu-west-1", 0},
+		{"any account", "123456789012.dkr.ecr.eu-west-1.amazonaws.com/", "", "eu-west-1", 0},
+		{"china", "123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn/", "123456789012", "cn-north-1", 0},
+		{"other account", "123456789012.dkr.ecr.eu-west-1.amazonaws.com/", "210987654321", "eu-west-1", 1},
+		{"other region", "123456789012.dkr.ecr.eu-west-1.amazonaws.com/", "123456789012", "us-east-1", 1},
+		{"empty region", "123456789012.dkr.ecr.eu-west-1.amazonaws.com/", "123456789012", "", 1},
+		{"unknown region", "123456789012.dkr.ecr.eu-west-1.amazonaws.com/", "123456789012", "moon-1", 1},
+		{"china partition", "123456789012.dkr.ecr.cn-north-1.amazonaws.com/", "123456789012", "cn-north-1", 1},
+		{"not ecr", "docker.io/your-name/", "123456789012", "eu-west-1", 1},
+	}
+
+	for _, c := range cases {
+		ref, err := parseRegistry(c.registry)
+		if err != nil {
+			t.Fatalf("%s: %s", c.name, err)
+		}
+		if errs := checkECR(ref, c.account, c.region); len(errs) != c.want {
+			t.Errorf("%s: want %d errors, got %v", c.name, c.want, errs)
+		}
+	}
+}
+
+func Test_checkECRConfig(t *testing.T) {
+	yml := types.InitYaml{Registry: "123456789012.dkr.ecr.eu-west-1.amazonaws.com/"}
+	if errs := checkECRConfig(yml); len(errs) != 0 {
+		t.Errorf("want no errors when ECR is disabled, got %v", errs)
+	}
+
+	yml.EnableECR = true
+	if errs := checkECRConfig(yml); len(errs) != 1 {
+		t.Errorf("want an error for the missing region, got %v", errs)
+	}
+
+	yml.ECRConfig.ECRRegion = "eu-west-1"
+	if errs := checkECRConfig(yml); len(errs) != 0 {
+		t.Errorf("want no errors, got %v", errs)
+	}
+}
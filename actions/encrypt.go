@@ -0,0 +1,326 @@
+package actions
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/burtonr/ofc-wizard/types"
+	"golang.org/x/crypto/scrypt"
+	"gopkg.in/AlecAivazis/survey.v1"
+	"gopkg.in/yaml.v2"
+)
+
+// Encrypted literal values are written as ENC[scrypt-aes256-gcm,<salt>,<nonce>,<data>], with
+// the key derived from a passphrase given by OFC_WIZARD_PASSPHRASE or asked for
+var (
+	encryptedPrefix = "ENC["
+	encryptedSuffix = "]"
+	encryptionType  = "scrypt-aes256-gcm"
+	passphraseEnv   = envPrefix + "PASSPHRASE"
+)
+
+var (
+	// encryptLiterals is set when a loaded file had encrypted values, so they are encrypted again when written
+	encryptLiterals bool
+	// confirmPassphrase is set when a new passphrase is chosen, so a typo can not lock the values away
+	confirmPassphrase bool
+	passphrase        string
+	derivedKeys       = map[string][]byte{}
+	// loadedCiphertexts keeps the encrypted form of each decrypted value, keyed by secret and
+	// key name, so unchanged values are written back as they were
+	loadedCiphertexts = map[string]encryptedLiteral{}
+)
+
+// encryptedLiteral is a decrypted literal value along with its encrypted form
+type encryptedLiteral struct {
+	Plain      string
+	Ciphertext string
+}
+
+func literalID(secret string, key string) string {
+	return secret + "/" + key
+}
+
+// slackURLID is the key of the Slack webhook URL in loadedCiphertexts. The URL holds the
+// webhook's token, so it is encrypted along with the literal values
+var slackURLID = "slack.url"
+
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix) && strings.HasSuffix(value, encryptedSuffix)
+}
+
+// getPassphrase returns the passphrase from the environment, or asks for it once
+func getPassphrase() (string, error) {
+	if passphrase != "" {
+		return passphrase, nil
+	}
+
+	if v, ok := os.LookupEnv(passphraseEnv); ok && v != "" {
+		passphrase = v
+		return passphrase, nil
+	}
+
+	question := &survey.Password{
+		Message: "Enter the passphrase for the encrypted secrets:",
+		Help:    fmt.Sprintf("Set %s to avoid being asked", passphraseEnv),
+	}
+	if err := survey.AskOne(question, &passphrase, survey.Required); err != nil {
+		return "", err
+	}
+
+	if confirmPassphrase {
+		confirm := ""
+		confirmQuestion := &survey.Password{Message: "Enter the passphrase again:"}
+		if err := survey.AskOne(confirmQuestion, &confirm, survey.Required); err != nil {
+			return "", err
+		}
+		if confirm != passphrase {
+			passphrase = ""
+			return "", errors.New("the passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+// deriveKey returns the AES-256 key for the passphrase and salt
+func deriveKey(salt []byte) ([]byte, error) {
+	if key, ok := derivedKeys[string(salt)]; ok {
+		return key, nil
+	}
+
+	pass, err := getPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := scrypt.Key([]byte(pass), salt, 32768, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	derivedKeys[string(salt)] = key
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptValue encrypts the value into the ENC[...] format
+func encryptValue(value string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := deriveKey(salt)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	data := gcm.Seal(nil, nonce, []byte(value), nil)
+	parts := []string{
+		encryptionType,
+		base64.StdEncoding.EncodeToString(salt),
+		base64.StdEncoding.EncodeToString(nonce),
+		base64.StdEncoding.EncodeToString(data),
+	}
+	return encryptedPrefix + strings.Join(parts, ",") + encryptedSuffix, nil
+}
+
+// decryptValue decrypts a value in the ENC[...] format
+func decryptValue(value string) (string, error) {
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(value, encryptedPrefix), encryptedSuffix), ",")
+	if len(parts) != 4 || parts[0] != encryptionType {
+		return "", errors.New("unknown encrypted value format")
+	}
+
+	decoded := make([][]byte, 3)
+	for i, p := range parts[1:] {
+		b, err := base64.StdEncoding.DecodeString(p)
+		if err != nil {
+			return "", err
+		}
+		decoded[i] = b
+	}
+	salt, nonce, data := decoded[0], decoded[1], decoded[2]
+
+	key, err := deriveKey(salt)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return "", errors.New("invalid nonce")
+	}
+
+	plain, err := gcm.Open(nil, nonce, data, nil)
+	if err != nil {
+		return "", errors.New("unable to decrypt, check the passphrase")
+	}
+	return string(plain), nil
+}
+
+// decryptSecrets decrypts the encrypted literal values of the secrets in place, remembering
+// to encrypt them again when they are written
+func decryptSecrets(secrets []types.Secret) error {
+	for i := range secrets {
+		for j, l := range secrets[i].Literals {
+			if !isEncrypted(l.Value) {
+				continue
+			}
+
+			plain, err := decryptLiteral(literalID(secrets[i].Name, l.Name), l.Value)
+			if err != nil {
+				return fmt.Errorf("secret %s key %s: %s", secrets[i].Name, l.Name, err.Error())
+			}
+			secrets[i].Literals[j].Value = plain
+		}
+	}
+	return nil
+}
+
+// decryptSlackURL decrypts the Slack webhook URL in place when it is encrypted
+func decryptSlackURL(slack *types.Slack) error {
+	if !isEncrypted(slack.URL) {
+		return nil
+	}
+
+	plain, err := decryptLiteral(slackURLID, slack.URL)
+	if err != nil {
+		return fmt.Errorf("slack.url: %s", err.Error())
+	}
+	slack.URL = plain
+	return nil
+}
+
+// decryptLiteral decrypts the value, remembering its encrypted form under the id
+func decryptLiteral(id string, value string) (string, error) {
+	plain, err := decryptValue(value)
+	if err != nil {
+		return "", err
+	}
+	loadedCiphertexts[id] = encryptedLiteral{Plain: plain, Ciphertext: value}
+	encryptLiterals = true
+	return plain, nil
+}
+
+// encryptLiteral encrypts the value, using its original encrypted form when it has not
+// changed since it was loaded
+func encryptLiteral(id string, value string) (string, error) {
+	if loaded, ok := loadedCiphertexts[id]; ok && loaded.Plain == value {
+		return loaded.Ciphertext, nil
+	}
+	return encryptValue(value)
+}
+
+// encryptSecrets returns a copy of the secrets with each literal value encrypted. Values
+// which have not changed since they were loaded keep their original encrypted form
+func encryptSecrets(secrets []types.Secret) ([]types.Secret, error) {
+	encrypted := make([]types.Secret, len(secrets))
+
+	for i, s := range secrets {
+		s.Literals = append([]types.Literal{}, s.Literals...)
+		for j, l := range s.Literals {
+			if l.Value == "" || isEncrypted(l.Value) || isVaultRef(l.Value) {
+				continue
+			}
+
+			value, err := encryptLiteral(literalID(s.Name, l.Name), l.Value)
+			if err != nil {
+				return nil, fmt.Errorf("secret %s key %s: %s", s.Name, l.Name, err.Error())
+			}
+			s.Literals[j].Value = value
+		}
+		encrypted[i] = s
+	}
+	return encrypted, nil
+}
+
+// secretsToWrite returns the secrets as they should be written, encrypted when the file
+// they were loaded from was encrypted
+func secretsToWrite(secrets []types.Secret) []types.Secret {
+	if !encryptLiterals {
+		return secrets
+	}
+
+	encrypted, err := encryptSecrets(secrets)
+	if err != nil {
+		exitWithError(err)
+	}
+	return encrypted
+}
+
+// slackURLToWrite returns the Slack webhook URL as it should be written, encrypted when the
+// file it was loaded from was encrypted
+func slackURLToWrite(url string) string {
+	if !encryptLiterals || url == "" || isEncrypted(url) {
+		return url
+	}
+
+	encrypted, err := encryptLiteral(slackURLID, url)
+	if err != nil {
+		exitWithError(fmt.Errorf("slack.url: %s", err.Error()))
+	}
+	return encrypted
+}
+
+// withSlackURL returns the document with its Slack webhook URL written as slackURLToWrite
+// returns it
+func withSlackURL(doc yaml.MapSlice, url string) yaml.MapSlice {
+	slack, _ := getKey(doc, "slack")
+	fields, ok := slack.(yaml.MapSlice)
+	if !ok || url == "" {
+		return doc
+	}
+	return setKey(doc, "slack", setKey(fields, "url", slackURLToWrite(url)))
+}
+
+// EncryptInitFile encrypts each literal value of the secrets, and the Slack webhook URL, in
+// the init.yml at the path
+func EncryptInitFile(path string) {
+	doc, yml := loadInitDoc(path)
+
+	// a plaintext file is encrypted with a new passphrase
+	confirmPassphrase = !encryptLiterals
+	encryptLiterals = true
+	doc.Doc = withSlackURL(doc.Doc, yml.Slack.URL)
+	writeSecrets(path, doc, yml.Secrets)
+}
+
+// DecryptInitFile writes the init.yml at the path with its encrypted values decrypted, for
+// use with ofc-bootstrap. The file is written to stdout when output is empty
+func DecryptInitFile(path string, output string) {
+	doc, yml := loadInitDoc(path)
+	encryptLiterals = false
+	doc.Doc = withSlackURL(doc.Doc, yml.Slack.URL)
+
+	if output == "" {
+		fmt.Print(string(marshalYamlDoc(doc, setKey(doc.Doc, "secrets", secretsDoc(doc.Doc, yml.Secrets)))))
+		return
+	}
+	writeSecrets(output, doc, yml.Secrets)
+}
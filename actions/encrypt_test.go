@@ -0,0 +1,149 @@
+package actions
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func useTestPassphrase() {
+	resetEncryption()
+	passphrase = "test passphrase"
+}
+
+func resetEncryption() {
+	passphrase = ""
+	encryptLiterals = false
+	derivedKeys = map[string][]byte{}
+	loadedCiphertexts = map[string]encryptedLiteral{}
+}
+
+func Test_encryptValue_RoundTrip(t *testing.T) {
+	useTestPassphrase()
+	defer resetEncryption()
+
+	encrypted, err := encryptValue("secret value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEncrypted(encrypted) {
+		t.Fatalf("want an ENC[...] value, got %s", encrypted)
+	}
+
+	plain, err := decryptValue(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain != "secret value" {
+		t.Errorf("want secret value, got %s", plain)
+	}
+}
+
+func Test_encryptSecrets_KeepsUnchangedCiphertext(t *testing.T) {
+	useTestPassphrase()
+	defer resetEncryption()
+
+	a, _ := encryptValue("a")
+	b, _ := encryptValue("b")
+	secrets := []types.Secret{{
+		Name:     "s",
+		Literals: []types.Literal{{Name: "a", Value: a}, {Name: "b", Value: b}},
+	}}
+
+	if err := decryptSecrets(secrets); err != nil {
+		t.Fatal(err)
+	}
+	secrets[0].Literals[1].Value = "changed"
+
+	encrypted, err := encryptSecrets(secrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if encrypted[0].Literals[0].Value != a {
+		t.Error("want the unchanged value to keep its ciphertext")
+	}
+	if encrypted[0].Literals[1].Value == b {
+		t.Error("want the changed value to be encrypted again")
+	}
+	if plain, _ := decryptValue(encrypted[0].Literals[1].Value); plain != "changed" {
+		t.Errorf("want changed, got %s", plain)
+	}
+}
+
+func Test_encryptSecrets_SkipsVaultRefs(t *testing.T) {
+	useTestPassphrase()
+	defer resetEncryption()
+
+	secrets := []types.Secret{{Name: "s", Literals: []types.Literal{{Name: "a", Value: "vault://secret/data/ofc#a"}}}}
+	encrypted, err := encryptSecrets(secrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted[0].Literals[0].Value != "vault://secret/data/ofc#a" {
+		t.Errorf("want the reference unchanged, got %s", encrypted[0].Literals[0].Value)
+	}
+}
+
+func Test_EncryptInitFile_RoundTrip(t *testing.T) {
+	useTestPassphrase()
+	defer resetEncryption()
+
+	var decrypted []byte
+	encrypted := editSecrets(t, func(path string) {
+		EncryptInitFile(path)
+
+		output := filepath.Join(filepath.Dir(path), "decrypted.yml")
+		DecryptInitFile(path, output)
+
+		var err error
+		if decrypted, err = ioutil.ReadFile(output); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// only the literal values are encrypted
+	want := regexp.MustCompile(`value: ENC\[[^\]]+\]`).ReplaceAllString(encrypted, "value: $$")
+	if want != regexp.MustCompile(`value: (abc|admin)`).ReplaceAllString(secretsSource, "value: $$") {
+		t.Errorf("want only the values encrypted, got:\n%s", encrypted)
+	}
+	if string(decrypted) != secretsSource {
+		t.Errorf("want the decrypted file to match the source, got:\n%s", decrypted)
+	}
+}
+
+func Test_EncryptInitFile_SlackURL(t *testing.T) {
+	useTestPassphrase()
+	defer resetEncryption()
+
+	path, remove := writeAuditInit(t, testSlackWebhook)
+	defer remove()
+
+	EncryptInitFile(path)
+	encrypted, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^slack:\n  url: ENC\[[^\]]+\]\n$`).Match(encrypted) {
+		t.Fatalf("want the webhook URL encrypted, got:\n%s", encrypted)
+	}
+
+	resetEncryption()
+	passphrase = "test passphrase"
+	if got := LoadInitFileFrom(path).Slack.URL; got != testSlackWebhook {
+		t.Errorf("want the webhook URL decrypted when loaded, got %s", got)
+	}
+
+	output := filepath.Join(filepath.Dir(path), "decrypted.yml")
+	DecryptInitFile(path, output)
+	decrypted, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(decrypted) != "slack:\n  url: "+testSlackWebhook+"\n" {
+		t.Errorf("want the plaintext webhook URL, got:\n%s", decrypted)
+	}
+}
@@ -0,0 +1,263 @@
+package actions
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/burtonr/ofc-wizard/types"
+	homedir "github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	k8sFormat            = "k8s"
+	swarmFormat          = "swarm"
+	defaultNamespace     = "openfaas"
+	functionsNamespace   = "openfaas-fn"
+	certManagerNamespace = "cert-manager"
+)
+
+// secretNamespaces maps the names of secrets read by functions to the namespace ofc-bootstrap
+// creates them in
+var secretNamespaces = map[string]string{
+	"s3-secret-key":   functionsNamespace,
+	"s3-access-key":   functionsNamespace,
+	"payload-secret":  functionsNamespace,
+	"registry-secret": functionsNamespace,
+}
+
+// filterNamespaces maps the filters of secrets read by functions, or by components outside
+// of OpenFaaS, to the namespace they must be created in
+var filterNamespaces = map[string]string{
+	githubFilter:    functionsNamespace,
+	gitlabFilter:    functionsNamespace,
+	"do_dns01":      certManagerNamespace,
+	"gcp_dns01":     certManagerNamespace,
+	"route53_dns01": certManagerNamespace,
+}
+
+type k8sSecret struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   k8sMetadata       `yaml:"metadata"`
+	Type       string            `yaml:"type"`
+	Data       map[string]string `yaml:"data"`
+}
+
+type k8sMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+// ExportSecrets writes the secrets of the init.yml at the path in the given format, only
+// including secrets with one of the filters when any are given, or with one of the filters
+// enabled by the init.yml when active is set. A dry run lists what would be created
+// without resolving any values
+func ExportSecrets(path string, format string, filters []string, active bool, dryRun bool, out io.Writer) {
+	yml := LoadInitFileFrom(path)
+	if active {
+		filters = append(filters, activeFilters(*yml)...)
+	}
+	secrets := filterSecrets(yml.Secrets, filters)
+
+	var err error
+	switch format {
+	case k8sFormat:
+		if dryRun {
+			err = listK8sSecrets(secrets, out)
+		} else {
+			err = writeK8sSecrets(secrets, out)
+		}
+	case swarmFormat:
+		if yml.Orchestration != swarm {
+			fmt.Fprintf(os.Stderr, "Warning: %s is configured for %s, not %s\n", path, yml.Orchestration, swarm)
+		}
+		for _, e := range checkOrchestrator(*yml) {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", e.Error())
+		}
+
+		if dryRun {
+			err = listSwarmSecrets(secrets, out)
+		} else {
+			err = writeSwarmScript(secrets, out)
+		}
+	default:
+		err = fmt.Errorf("Unknown format %q, expected %s or %s", format, k8sFormat, swarmFormat)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+// filterSecrets returns the secrets which have at least one of the filters, or all of the
+// secrets when there are no filters
+func filterSecrets(secrets []types.Secret, filters []string) []types.Secret {
+	if len(filters) == 0 {
+		return secrets
+	}
+
+	filtered := []types.Secret{}
+	for _, s := range secrets {
+		if hasAnyFilter(s, filters) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+func hasAnyFilter(secret types.Secret, filters []string) bool {
+	for _, f := range secret.Filters {
+		for _, want := range filters {
+			if f == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// secretNamespace returns the namespace of the secret, defaulting it from its name or filters
+func secretNamespace(secret types.Secret) string {
+	if secret.Namespace != "" {
+		return secret.Namespace
+	}
+	if ns, ok := secretNamespaces[secret.Name]; ok {
+		return ns
+	}
+
+	for _, f := range secret.Filters {
+		if ns, ok := filterNamespaces[f]; ok {
+			return ns
+		}
+	}
+	return defaultNamespace
+}
+
+// resolveSecret returns the value of each key of the secret, reading files and running
+// commands the same way as ofc-bootstrap, and reading Vault references from Vault
+func resolveSecret(secret types.Secret) (map[string][]byte, error) {
+	values := map[string][]byte{}
+
+	for _, l := range secret.Literals {
+		value, err := resolveLiteral(l)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s: %s", secret.Name, err.Error())
+		}
+		values[l.Name] = []byte(value)
+	}
+
+	for _, f := range secret.Files {
+		value, err := resolveFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s: %s", secret.Name, err.Error())
+		}
+		values[f.Name] = value
+	}
+	return values, nil
+}
+
+// resolveSecretKey returns the value of a literal key, or of a file key kept in Vault
+func resolveSecretKey(key secretKey) (string, error) {
+	if key.File == nil {
+		return resolveLiteral(key.Value)
+	}
+
+	value, err := resolveFile(*key.File)
+	return string(value), err
+}
+
+// resolveLiteral returns the value of the literal, read from Vault when it is a Vault reference
+func resolveLiteral(literal types.Literal) (string, error) {
+	if !isVaultRef(literal.Value) {
+		return literal.Value, nil
+	}
+
+	value, err := resolveVaultRef(literal.Value)
+	if err != nil {
+		return "", fmt.Errorf("value for %s: %s", literal.Name, err.Error())
+	}
+	return value, nil
+}
+
+// resolveFile runs the value_command of the file, if any, then reads the value_from file,
+// or reads the value from Vault when value_from is a Vault reference
+func resolveFile(file types.FileValue) ([]byte, error) {
+	if isVaultRef(file.ValueFrom) {
+		value, err := resolveVaultRef(file.ValueFrom)
+		if err != nil {
+			return nil, fmt.Errorf("value_from for %s: %s", file.Name, err.Error())
+		}
+		return []byte(value), nil
+	}
+
+	if file.ValueCommand != "" {
+		cmd := exec.Command("/bin/sh", "-c", file.ValueCommand)
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("value_command for %s failed: %s", file.Name, err.Error())
+		}
+	}
+
+	path, err := homedir.Expand(file.ValueFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read value_from for %s: %s", file.Name, err.Error())
+	}
+	return value, nil
+}
+
+// listK8sSecrets prints the name, namespace and keys of each Secret that would be exported
+func listK8sSecrets(secrets []types.Secret, out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SECRET\tNAMESPACE\tKEY\tSOURCE")
+	for _, s := range secrets {
+		for _, key := range secretKeys(s) {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, secretNamespace(s), key.Name, key.Source)
+		}
+	}
+	return w.Flush()
+}
+
+// writeK8sSecrets writes a Kubernetes Secret manifest for each secret, as a multi-document yaml
+func writeK8sSecrets(secrets []types.Secret, out io.Writer) error {
+	docs := []string{}
+
+	for _, s := range secrets {
+		values, err := resolveSecret(s)
+		if err != nil {
+			return err
+		}
+
+		manifest := k8sSecret{
+			APIVersion: "v1",
+			Kind:       "Secret",
+			Metadata:   k8sMetadata{Name: s.Name, Namespace: secretNamespace(s)},
+			Type:       "Opaque",
+			Data:       map[string]string{},
+		}
+		for k, v := range values {
+			manifest.Data[k] = base64.StdEncoding.EncodeToString(v)
+		}
+
+		doc, err := yaml.Marshal(manifest)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, string(doc))
+	}
+
+	_, err := fmt.Fprint(out, strings.Join(docs, "---\n"))
+	return err
+}
@@ -0,0 +1,29 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func Test_secretNamespace(t *testing.T) {
+	cases := []struct {
+		secret types.Secret
+		want   string
+	}{
+		{types.Secret{Name: "github-webhook-secret", Filters: []string{"scm_github"}}, "openfaas-fn"},
+		{types.Secret{Name: "private-key", Filters: []string{"scm_github"}}, "openfaas-fn"},
+		{types.Secret{Name: "gitlab-webhook-secret", Filters: []string{"scm_gitlab"}}, "openfaas-fn"},
+		{types.Secret{Name: "payload-secret", Filters: []string{"default"}}, "openfaas-fn"},
+		{types.Secret{Name: "basic-auth", Filters: []string{"default"}}, "openfaas"},
+		{types.Secret{Name: "of-client-secret", Filters: []string{"auth"}}, "openfaas"},
+		{types.Secret{Name: "digitalocean-dns", Filters: []string{"do_dns01"}}, "cert-manager"},
+		{types.Secret{Name: "private-key", Filters: []string{"scm_github"}, Namespace: "custom"}, "custom"},
+	}
+
+	for _, c := range cases {
+		if got := secretNamespace(c.secret); got != c.want {
+			t.Errorf("%s: want %s, got %s", c.secret.Name, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package actions
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+// Feature filters used by ofc-bootstrap to decide which secrets to create
+var (
+	defaultFilter = "default"
+	githubFilter  = "scm_github"
+	gitlabFilter  = "scm_gitlab"
+	authFilter    = "auth"
+	ecrFilter     = "ecr"
+	// s3Filter groups the S3 storage keys, which ofc-bootstrap creates with the default filter
+	s3Filter = "s3"
+)
+
+// requiredSecrets lists the secrets ofc-bootstrap needs for each feature filter
+var requiredSecrets = map[string][]string{
+	defaultFilter:         {"s3-secret-key", "s3-access-key", "basic-auth", "payload-secret", "registry-secret"},
+	githubFilter:          {"github-webhook-secret", "private-key"},
+	gitlabFilter:          {"gitlab-webhook-secret"},
+	authFilter:            {"jwt-private-key", "jwt-public-key", "of-client-secret"},
+	ecrFilter:             {ecrSecretName},
+	s3Filter:              {"s3-secret-key", "s3-access-key"},
+	customersFilter:       {customersSecretName},
+	digOceanDNS.Filter[0]: {digOceanDNS.Name},
+	gCloudDNS.Filter[0]:   {gCloudDNS.Name},
+	awsDNS.Filter[0]:      {awsDNS.Name},
+}
+
+// activeFilters returns the feature filters enabled by the settings of the init.yml
+func activeFilters(yml types.InitYaml) []string {
+	filters := []string{defaultFilter}
+
+	switch yml.SCM {
+	case github:
+		filters = append(filters, githubFilter)
+	case gitlab:
+		filters = append(filters, gitlabFilter)
+	}
+
+	if yml.EnableOAuth {
+		filters = append(filters, authFilter)
+	}
+
+	if yml.EnableECR {
+		filters = append(filters, ecrFilter)
+	}
+
+	if yml.CustomersSecret {
+		filters = append(filters, customersFilter)
+	}
+
+	if yml.S3.S3URL != "" {
+		filters = append(filters, s3Filter)
+	}
+
+	if yml.TLS {
+		for _, p := range []dnsProvider{digOceanDNS, gCloudDNS, awsDNS} {
+			if p.Name == yml.TLSConfig.DNSService {
+				filters = append(filters, p.Filter...)
+			}
+		}
+	}
+	return filters
+}
+
+// secretStatus describes whether ofc-bootstrap will create a secret
+type secretStatus struct {
+	Name    string
+	Filters []string
+	Status  string
+}
+
+// Statuses of a secret
+var (
+	statusCreate  = "create"
+	statusSkip    = "skip"
+	statusMissing = "missing"
+)
+
+// secretStatuses returns whether each secret of the init.yml will be created or skipped
+// with the active filters, followed by the required secrets which are missing
+func secretStatuses(yml types.InitYaml) []secretStatus {
+	filters := activeFilters(yml)
+	statuses := []secretStatus{}
+	existing := map[string]bool{}
+
+	for _, s := range yml.Secrets {
+		existing[s.Name] = true
+
+		status := statusSkip
+		if hasAnyFilter(s, filters) {
+			status = statusCreate
+		}
+		statuses = append(statuses, secretStatus{Name: s.Name, Filters: s.Filters, Status: status})
+	}
+
+	for _, f := range filters {
+		for _, name := range requiredSecrets[f] {
+			// images are pushed to ECR with the AWS credentials instead
+			if name == registrySecretName && yml.EnableECR {
+				continue
+			}
+			if !existing[name] {
+				existing[name] = true
+				statuses = append(statuses, secretStatus{Name: name, Filters: []string{f}, Status: statusMissing})
+			}
+		}
+	}
+	return statuses
+}
+
+// filterStatuses returns the statuses of the secrets with one of the filters, or required by
+// one of them. All of the statuses are returned when there are no filters
+func filterStatuses(statuses []secretStatus, filters []string) []secretStatus {
+	if len(filters) == 0 {
+		return statuses
+	}
+
+	filtered := []secretStatus{}
+	for _, s := range statuses {
+		for _, f := range filters {
+			if contains(s.Filters, f) || contains(requiredSecrets[f], s.Name) {
+				filtered = append(filtered, s)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// ListSecrets prints which secrets of the init.yml at the path will be created, which will
+// be skipped and which required secrets are missing, only listing the secrets for the
+// filters when they are given
+func ListSecrets(path string, filters []string, out io.Writer) {
+	yml := LoadInitFileFrom(path)
+
+	fmt.Fprintf(out, "Active filters: %s\n\n", strings.Join(activeFilters(*yml), ", "))
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SECRET\tFILTERS\tSTATUS")
+	for _, s := range filterStatuses(secretStatuses(*yml), filters) {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, strings.Join(s.Filters, ","), s.Status)
+	}
+	w.Flush()
+}
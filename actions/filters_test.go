@@ -0,0 +1,103 @@
+package actions
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func Test_activeFilters(t *testing.T) {
+	yml := types.InitYaml{
+		SCM:         gitlab,
+		EnableOAuth: true,
+		TLS:         true,
+		TLSConfig:   types.TLSConfig{DNSService: awsDNS.Name},
+	}
+
+	want := []string{defaultFilter, gitlabFilter, authFilter, "route53_dns01"}
+	if got := activeFilters(yml); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func Test_activeFilters_DNSNeedsTLS(t *testing.T) {
+	yml := types.InitYaml{
+		SCM:       github,
+		TLSConfig: types.TLSConfig{DNSService: digOceanDNS.Name},
+	}
+
+	want := []string{defaultFilter, githubFilter}
+	if got := activeFilters(yml); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func Test_dnsSecret(t *testing.T) {
+	answers := &dnsAnswers{
+		Name:       gCloudDNS.Name,
+		Filters:    gCloudDNS.Filter,
+		AccessFile: types.Literal{Name: gCloudDNS.File, Value: "~/service-account.json"},
+		Namespace:  certManagerNamespace,
+	}
+
+	secret := dnsSecret(answers)
+	if secret.Name != "clouddns-service-account" || secret.Namespace != "cert-manager" {
+		t.Errorf("unexpected secret %+v", secret)
+	}
+	if len(secret.Files) != 1 || secret.Files[0].ValueFrom != "~/service-account.json" {
+		t.Errorf("want the credentials file, got %+v", secret.Files)
+	}
+	if !hasAnyFilter(secret, []string{"gcp_dns01"}) {
+		t.Errorf("want the gcp_dns01 filter, got %v", secret.Filters)
+	}
+}
+
+func Test_activeFilters_S3(t *testing.T) {
+	yml := types.InitYaml{
+		EnableECR:       true,
+		CustomersSecret: true,
+		S3:              types.Storage{S3URL: "s3.amazonaws.com"},
+	}
+
+	want := []string{defaultFilter, ecrFilter, customersFilter, s3Filter}
+	if got := activeFilters(yml); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func Test_secretStatuses_MissingOnce(t *testing.T) {
+	yml := types.InitYaml{S3: types.Storage{S3URL: "s3.amazonaws.com"}}
+
+	missing := map[string]int{}
+	for _, s := range secretStatuses(yml) {
+		missing[s.Name]++
+	}
+	if missing["s3-secret-key"] != 1 || missing["s3-access-key"] != 1 {
+		t.Errorf("want the S3 keys missing once, got %v", missing)
+	}
+}
+
+func Test_filterStatuses(t *testing.T) {
+	yml := types.InitYaml{
+		S3: types.Storage{S3URL: "s3.amazonaws.com"},
+		Secrets: []types.Secret{
+			{Name: "s3-secret-key", Filters: []string{defaultFilter}},
+			{Name: "payload-secret", Filters: []string{defaultFilter}},
+			{Name: "my-storage", Filters: []string{defaultFilter, s3Filter}},
+		},
+	}
+
+	names := []string{}
+	for _, s := range filterStatuses(secretStatuses(yml), []string{s3Filter}) {
+		names = append(names, s.Name)
+	}
+	want := []string{"s3-secret-key", "my-storage", "s3-access-key"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("want %v, got %v", want, names)
+	}
+
+	if all := filterStatuses(secretStatuses(yml), nil); len(all) != len(secretStatuses(yml)) {
+		t.Errorf("want every status without filters, got %v", all)
+	}
+}
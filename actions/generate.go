@@ -3,6 +3,7 @@ package actions
 import (
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/burtonr/ofc-wizard/types"
@@ -18,9 +19,11 @@ type initialAnswers struct {
 }
 
 type githubAnswers struct {
+	AppCreated     bool
 	AppID          string
 	WebhookSecret  string
 	PrivateKeyFrom string
+	Organisation   string
 }
 
 type gitlabAnswers struct {
@@ -60,11 +63,15 @@ type tlsAnswers struct {
 type configAnswers struct {
 	AuditURL        string
 	CustomersURL    string
+	CustomersSecret bool
+	CustomersFile   string
 	UseDockerfile   bool
 	OFVersion       string
 	ScaleZero       bool
 	NetworkPolicies bool
 	Ingress         string
+	BuildBranch     string
+	CustomTemplates []string
 }
 
 type dnsProvider struct {
@@ -81,8 +88,10 @@ var (
 	github              = "github"
 	gitlab              = "gitlab"
 	defaultVersion      = "0.9.7"
-	createAppHelpText   = "Create a Github app by following the instructions in the docs: https://docs.openfaas.com/openfaas-cloud/self-hosted/github/"
-	createOAuthHelpText = "Create the OAuth App on your source control management system"
+	defaultS3URL        = "cloud-minio.openfaas.svc.cluster.local:9000"
+	defaultBuildBranch  = "master"
+	createAppHelpText   = "A page to create the Github app with the settings OpenFaaS Cloud needs is written after the remaining questions. See the docs for more: https://docs.openfaas.com/openfaas-cloud/self-hosted/github/"
+	createOAuthHelpText = "Create the OAuth App with the settings above, then enter its ID"
 	digOceanDNS         = dnsProvider{
 		FriendlyName: "DigitalOcean",
 		Name:         "digitalocean-dns",
@@ -109,36 +118,87 @@ var (
 // GenerateYaml will create and ask the survey questions to generate a yml file for use with the ofc-bootstrap tool
 func GenerateYaml() {
 	yml := CreateInitFile()
+	existing := *yml
+	existingYaml = &existing
 
 	initAnswers, err := askInitialQuestions()
-
 	if err != nil {
-		fmt.Println(err.Error())
+		exitWithError(err)
 	}
 
 	yml.Orchestration = initAnswers.Orchestrator
-	yml.RootDomain = initAnswers.RootDomain
-	yml.Registry = initAnswers.Registry
+	yml.RootDomain = normaliseDomain(initAnswers.RootDomain)
+	if warning := apexDomainWarning(yml.RootDomain); warning != "" {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+	registry, err := parseRegistry(initAnswers.Registry)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	yml.Registry = registry.String()
 	yml.SCM = initAnswers.SourceControl
 	yml.EnableOAuth = initAnswers.EnableOAuth
 
+	if registry.Type != otherRegistry {
+		fmt.Printf("Using %s as %s\n", yml.Registry, registry.Type.Name)
+	}
+
+	registrySecret, registrySource, err := askRegistryCredentials(registry, yml.Secrets)
+	if err != nil {
+		fmt.Println(err.Error())
+	} else if registrySecret != nil {
+		addSecret(yml, *registrySecret, registrySource)
+	}
+
+	yml.EnableECR = registry.Type == ecrRegistry
+	yml.ECRConfig = types.ECRConfig{}
+
+	if yml.EnableECR {
+		// enable_ecr without a region would fail in ofc-bootstrap, so an interrupted answer stops here
+		ecrAnswers, err := askECRQuestions(registry)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		if errs := checkECR(registry, ecrAnswers.Account, ecrAnswers.Region); len(errs) > 0 {
+			for _, e := range errs {
+				fmt.Println(e.Error())
+			}
+			os.Exit(1)
+		}
+
+		yml.ECRConfig.ECRRegion = ecrAnswers.Region
+		addSecret(yml, ecrSecret(ecrAnswers.CredentialsFile), sourcePrompt)
+	}
+
+	var ghAnswers *githubAnswers
 	if initAnswers.SourceControl == github {
-		ghAnswers := askGithubQuestions()
+		ghAnswers = askGithubQuestions()
 		yml.Github = types.Github{
 			AppID: ghAnswers.AppID,
 		}
+
+		secrets, err := githubSecrets(yml.Secrets, ghAnswers)
+		if err != nil {
+			exitWithError(err)
+		}
+		for _, s := range secrets {
+			addSecret(yml, s, sourcePrompt)
+		}
 	} else if initAnswers.SourceControl == gitlab {
 		glAnswers := askGitLabQuestions()
 		yml.GitLab = types.GitLab{
-			GitLabInstance: glAnswers.Instance,
+			GitLabInstance: normaliseGitLabInstance(glAnswers.Instance),
 		}
-	}
 
-	if initAnswers.EnableOAuth {
-		oAuthAnswers := askOAuthQuestions(initAnswers.SourceControl)
-		yml.OAuth = types.OAuth{
-			ClientID:             oAuthAnswers.ClientID,
-			OAuthProviderBaseURL: oAuthAnswers.BaseURL,
+		secret, err := gitlabSecret(yml.Secrets, glAnswers)
+		if err != nil {
+			exitWithError(err)
+		}
+		addSecret(yml, secret, sourcePrompt)
+		if warning := gitlabURLWarning(yml.GitLab.GitLabInstance); warning != "" {
+			fmt.Printf("Warning: %s\n", warning)
 		}
 	}
 
@@ -150,10 +210,21 @@ func GenerateYaml() {
 		S3TLS:    storageAnswers.EnableTLS,
 	}
 
-	dnsAnswers := askDNSQuestions()
-	tlsAnswers := askTLSQuestions(dnsAnswers.Name)
+	// TLS certificates are issued by cert-manager, using the DNS provider
+	tlsAnswers := &tlsAnswers{}
+	if appliesTo(yml.Orchestration, "tls") {
+		dnsAnswers := askDNSQuestions()
+		tlsAnswers, err = askTLSQuestions(dnsAnswers.Name)
+		if err != nil {
+			exitWithError(err)
+		}
+		if tlsAnswers.Enabled {
+			addSecret(yml, dnsSecret(dnsAnswers), sourcePrompt)
+		}
+	}
 
 	yml.TLS = tlsAnswers.Enabled
+	yml.TLSConfig = types.TLSConfig{}
 
 	if yml.TLS {
 		yml.TLSConfig = types.TLSConfig{
@@ -166,17 +237,88 @@ func GenerateYaml() {
 		}
 	}
 
-	// finalConfigAnswers := askFinalConfigQuestions()
+	// settings chosen earlier may need a newer version than the default
+	needs := []string{}
+	if yml.EnableECR {
+		needs = append(needs, "enable_ecr")
+	}
+
+	finalConfigAnswers, err := askFinalConfigQuestions(yml.Orchestration, yml.SCM, needs)
+	if err != nil {
+		exitWithError(err)
+	}
 
-	// // Println statements to avoid "unused" errors (temporary)
-	// fmt.Println("Orchestration:", initAnswers.Orchestrator)
-	// fmt.Println("Root Domain:", initAnswers.RootDomain)
-	// fmt.Println("Registry:", initAnswers.Registry)
-	// fmt.Println("Source Control:", initAnswers.SourceControl)
-	// fmt.Println("Storage Answers:", storageAnswers)
-	// fmt.Println("DNS Answers:", dnsAnswers)
-	// fmt.Println("TLS Answers:", tlsAnswers)
-	// fmt.Println("Final Answers:", finalConfigAnswers)
+	yml.Slack.URL = finalConfigAnswers.AuditURL
+	yml.CustomersURL = finalConfigAnswers.CustomersURL
+	yml.CustomersSecret = finalConfigAnswers.CustomersSecret
+	if yml.CustomersSecret {
+		yml.CustomersURL = ""
+		addSecret(yml, customersSecret(finalConfigAnswers.CustomersFile), sourcePrompt)
+	}
+	yml.EnableDockerFile = finalConfigAnswers.UseDockerfile
+	yml.ScaleToZero = finalConfigAnswers.ScaleZero
+	yml.OpenFaaSCloudVersion = finalConfigAnswers.OFVersion
+	yml.NetworkPolicies = finalConfigAnswers.NetworkPolicies
+	yml.Ingress = finalConfigAnswers.Ingress
+	yml.BuildBranch = finalConfigAnswers.BuildBranch
+	yml.Deployment.CustomTemplates = finalConfigAnswers.CustomTemplates
+
+	// the OAuth App URLs depend on the TLS and ingress answers
+	if initAnswers.EnableOAuth {
+		oAuthAnswers := askOAuthQuestions(*yml)
+		yml.OAuth = types.OAuth{
+			ClientID:             oAuthAnswers.ClientID,
+			OAuthProviderBaseURL: oAuthAnswers.BaseURL,
+		}
+		if initAnswers.SourceControl == gitlab {
+			yml.OAuth.OAuthProviderBaseURL = gitlabOAuthBaseURL(oAuthAnswers.BaseURL)
+		}
+	}
+
+	ymlSchema, err := schemaFor(yml.OpenFaaSCloudVersion)
+	if err != nil {
+		exitWithError(err)
+	}
+	yml.SchemaVersion = ymlSchema.Version
+	if errs := checkSchema(*yml, ymlSchema); len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Println(e.Error())
+		}
+		os.Exit(1)
+	}
+
+	recordUnansweredSources(*yml)
+	WriteInitFile(*yml)
+	writeProvenance()
+	printDNSPlan(*yml)
+
+	if ghAnswers != nil && !ghAnswers.AppCreated {
+		fmt.Println()
+		if err := writeGitHubApp(*yml, ghAnswers.Organisation); err != nil {
+			fmt.Println(err.Error())
+		}
+		fmt.Println("Once the app is created, run 'ofc-wizard generate' again to enter its App ID and private key")
+	}
+
+	if yml.SCM == gitlab {
+		fmt.Println()
+		writeChecklist(gitlabChecklistTitle(*yml), gitlabChecklist(*yml), os.Stdout)
+		fmt.Println("\nRun 'ofc-wizard export gitlab-checklist > gitlab-setup.md' to save the checklist")
+	}
+
+	if yml.Orchestration == swarm {
+		for _, e := range checkOrchestrator(*yml) {
+			fmt.Printf("Warning: %s\n", e.Error())
+		}
+		fmt.Println("Run 'ofc-wizard export secrets --format swarm' to create a script for the Docker Swarm secrets")
+	}
+}
+
+// addSecret adds the secret asked for by the wizard to the init.yml, recording where it came from
+func addSecret(yml *types.InitYaml, secret types.Secret, source string) {
+	secret = orchestratorSecret(yml.Orchestration, secret)
+	yml.Secrets = putSecret(yml.Secrets, secret)
+	recordSecretSource(secret, source)
 }
 
 type answers struct {
@@ -196,17 +338,15 @@ func askInitialQuestions() (*initialAnswers, error) {
 		{
 			Name:     "RootDomain",
 			Prompt:   &survey.Input{Message: "Root Domain (eg: faas.example.com):"},
-			Validate: survey.Required,
+			Validate: validateRootDomain,
 		},
 		{
-			Name:   "Registry",
-			Prompt: &survey.Input{Message: "Registry to publish images (eg: docker.io/your-name/):"},
-			Validate: func(val interface{}) error {
-				if str, ok := val.(string); !ok || !strings.HasSuffix(str, "/") {
-					return errors.New("The registry address must end with a '/'")
-				}
-				return nil
+			Name: "Registry",
+			Prompt: &survey.Input{
+				Message: "Registry to publish images (eg: docker.io/your-name/):",
+				Help:    "Docker Hub, GitHub (ghcr.io), Amazon ECR, Google (gcr.io), Quay or your own registry as host[:port]/namespace/",
 			},
+			Validate: validateRegistry,
 		},
 		{
 			Name: "SourceControl",
@@ -222,9 +362,17 @@ func askInitialQuestions() (*initialAnswers, error) {
 		},
 	}
 
+	keys := map[string]string{
+		"Orchestrator":  orchestrationKey,
+		"RootDomain":    rootDomainKey,
+		"Registry":      registryKey,
+		"SourceControl": scmKey,
+		"EnableOAuth":   enableOAuthKey,
+	}
+
 	a := &initialAnswers{}
 
-	if err := survey.Ask(questions, a); err != nil {
+	if err := ask(questions, keys, a); err != nil {
 		return nil, err
 	}
 	return a, nil
@@ -233,8 +381,10 @@ func askInitialQuestions() (*initialAnswers, error) {
 func askGithubQuestions() *githubAnswers {
 	var preReqQuestion = &survey.Confirm{Message: "Do you have your Github App setup already?"}
 
-	appCreated := false
-	survey.AskOne(preReqQuestion, &appCreated, nil)
+	appCreated := isPreset(githubAppIDKey)
+	if !appCreated {
+		survey.AskOne(preReqQuestion, &appCreated, nil)
+	}
 
 	if !appCreated {
 		fmt.Printf("\n%s\n\n", createAppHelpText)
@@ -260,11 +410,28 @@ func askGithubQuestions() *githubAnswers {
 		},
 	}
 
-	a := &githubAnswers{}
+	// the app id and private key only exist once the app is created
+	if !appCreated {
+		questions = []*survey.Question{
+			questions[1],
+			{
+				Name:   "Organisation",
+				Prompt: &survey.Input{Message: "Enter the Github organisation to create the app in (leave blank for your user account):"},
+			},
+		}
+	}
 
-	if err := survey.Ask(questions, a); err != nil {
-		fmt.Println(err.Error())
-		return nil
+	keys := map[string]string{
+		"AppID":          githubAppIDKey,
+		"WebhookSecret":  githubWebhookSecretKey,
+		"PrivateKeyFrom": githubPrivateKeyKey,
+		"Organisation":   githubOrgKey,
+	}
+
+	a := &githubAnswers{AppCreated: appCreated}
+
+	if err := ask(questions, keys, a); err != nil {
+		exitWithError(err)
 	}
 	return a
 }
@@ -281,27 +448,36 @@ func askGitLabQuestions() *gitlabAnswers {
 		{
 			Name: "Instance",
 			Prompt: &survey.Input{
-				Message: "Enter the public URL for your GitLab instance (with trailing slash):",
+				Message: "Enter the public URL for your GitLab instance:",
 				Help:    "Enter the full URL of your public GitLab (eg: https://gitlab.example.com/)",
 			},
-			Validate: survey.Required,
+			Validate: validateGitLabURL,
 		},
 	}
 
+	keys := map[string]string{
+		"WebhookSecret": gitlabWebhookSecretKey,
+		"Instance":      gitlabInstanceKey,
+	}
+
 	a := &gitlabAnswers{}
 
-	if err := survey.Ask(questions, a); err != nil {
-		fmt.Println(err.Error())
-		return nil
+	if err := ask(questions, keys, a); err != nil {
+		exitWithError(err)
 	}
 	return a
 }
 
-func askOAuthQuestions(scm string) *oauthAnswers {
+func askOAuthQuestions(yml types.InitYaml) *oauthAnswers {
 	var preReqQuestion = &survey.Confirm{Message: "Have you created your OAuth App already?"}
 
-	appCreated := false
-	survey.AskOne(preReqQuestion, &appCreated, nil)
+	fmt.Println()
+	printOAuthAppSettings(yml)
+
+	appCreated := isPreset(oauthClientIDKey)
+	if !appCreated {
+		survey.AskOne(preReqQuestion, &appCreated, nil)
+	}
 
 	if !appCreated {
 		fmt.Printf("\n%s\n\n", createOAuthHelpText)
@@ -314,30 +490,35 @@ func askOAuthQuestions(scm string) *oauthAnswers {
 		},
 	}
 
-	if scm == gitlab {
-		baseQ := survey.Question{Name: "BaseURL", Prompt: &survey.Input{Message: "Enter your OAuth Provider Base URL:"}}
-		questions = append(questions, &baseQ)
+	if yml.SCM == gitlab {
+		questions = append(questions, gitlabBaseURLQuestion(yml.GitLab.GitLabInstance))
+	}
+
+	keys := map[string]string{
+		"ClientID": oauthClientIDKey,
+		"BaseURL":  oauthProviderBaseURLKey,
 	}
 
 	a := &oauthAnswers{}
 
-	if err := survey.Ask(questions, a); err != nil {
-		fmt.Println(err.Error())
-		return nil
+	if err := ask(questions, keys, a); err != nil {
+		exitWithError(err)
 	}
 	return a
 }
 
 func askStorageQuestions() *storageAnswers {
 	answers := &storageAnswers{
-		URL:       "cloud-minio.openfaas.svc.cluster.local:9000",
+		URL:       defaultS3URL,
 		Region:    "us-east-1",
 		EnableTLS: false,
 		Bucket:    "pipeline"}
 
 	customStorageQuestion := &survey.Confirm{Message: "Would you like to use custom storage (S3 compatible) for logs from buildkit? (not recommended)"}
 	customStorage := false
-	survey.AskOne(customStorageQuestion, &customStorage, nil)
+	if err := askOne(customStorageKey, customStorageQuestion, &customStorage, nil); err != nil {
+		exitWithError(err)
+	}
 
 	if customStorage {
 		storageQuestions := []*survey.Question{
@@ -359,9 +540,15 @@ func askStorageQuestions() *storageAnswers {
 			},
 		}
 
-		if err := survey.Ask(storageQuestions, answers); err != nil {
-			fmt.Println(err.Error())
-			return nil
+		keys := map[string]string{
+			"URL":       s3URLKey,
+			"Region":    s3RegionKey,
+			"Bucket":    s3BucketKey,
+			"EnableTLS": s3TLSKey,
+		}
+
+		if err := ask(storageQuestions, keys, answers); err != nil {
+			exitWithError(err)
 		}
 	}
 
@@ -374,30 +561,46 @@ func askDNSQuestions() *dnsAnswers {
 
 	nameQuestion := &survey.Select{Message: "Select a DNS provider:", Options: dnsNames}
 	var name string
-	survey.AskOne(nameQuestion, &name, nil)
+	if err := askOne(dnsProviderKey, nameQuestion, &name, nil); err != nil {
+		exitWithError(err)
+	}
 
 	fileQuestion := &survey.Input{
 		Message: "Enter the path to the file containing the DNS provider credentials:",
 		Help:    providers[name].HelpText,
 	}
 	var fileName string
-	survey.AskOne(fileQuestion, &fileName, nil)
+	if err := askOne(dnsCredentialsFileKey, fileQuestion, &fileName, nil); err != nil {
+		exitWithError(err)
+	}
 
 	selectedProvider := providers[name]
 	resultFileLit := types.Literal{Name: selectedProvider.File, Value: fileName}
-	result := &dnsAnswers{Name: selectedProvider.Name, Filters: selectedProvider.Filter, AccessFile: resultFileLit}
+	result := &dnsAnswers{Name: selectedProvider.Name, Filters: selectedProvider.Filter, AccessFile: resultFileLit, Namespace: certManagerNamespace}
 
 	return result
 }
 
-func askTLSQuestions(dnsService string) *tlsAnswers {
-	answers := &tlsAnswers{Enabled: false}
+// dnsSecret returns the secret cert-manager reads the DNS provider credentials from
+func dnsSecret(answers *dnsAnswers) types.Secret {
+	return types.Secret{
+		Name:      answers.Name,
+		Files:     []types.FileValue{{Name: answers.AccessFile.Name, ValueFrom: answers.AccessFile.Value}},
+		Filters:   answers.Filters,
+		Namespace: answers.Namespace,
+	}
+}
+
+func askTLSQuestions(dnsService string) (*tlsAnswers, error) {
+	answers := &tlsAnswers{Enabled: false, DNSService: dnsService}
 
 	enableTLSQuestion := &survey.Confirm{Message: "Would you like to enable TLS? (recommended)"}
-	survey.AskOne(enableTLSQuestion, &answers.Enabled, nil)
+	if err := askOne(tlsKey, enableTLSQuestion, &answers.Enabled, nil); err != nil {
+		return nil, err
+	}
 
 	if !answers.Enabled {
-		return answers
+		return answers, nil
 	}
 
 	tlsConfigQuestions := []*survey.Question{
@@ -420,48 +623,118 @@ func askTLSQuestions(dnsService string) *tlsAnswers {
 		},
 	}
 
-	survey.Ask(tlsConfigQuestions, answers)
+	keys := map[string]string{
+		"EmailAddress": tlsEmailKey,
+		"IssuerType":   tlsIssuerTypeKey,
+	}
+
+	if err := ask(tlsConfigQuestions, keys, answers); err != nil {
+		return nil, err
+	}
 
 	switch dnsService {
 	case gCloudDNS.Name:
-		survey.AskOne(&survey.Input{Message: "Enter the Project ID:"}, &answers.ProjectID, nil)
-		break
+		if err := askOne(gcpProjectIDKey, &survey.Input{Message: "Enter the Project ID:"}, &answers.ProjectID, nil); err != nil {
+			return nil, err
+		}
 	case awsDNS.Name:
 		awsConfigQuestions := []*survey.Question{
 			{Name: "Region", Prompt: &survey.Input{Message: "Enter the AWS Region:"}},
 			{Name: "AccessKey", Prompt: &survey.Input{Message: "Enter the Access Key ID:"}},
 		}
-		survey.Ask(awsConfigQuestions, answers)
-		break
-	case digOceanDNS.Name:
-		return answers
+		if err := ask(awsConfigQuestions, map[string]string{"Region": awsRegionKey, "AccessKey": awsAccessKeyIDKey}, answers); err != nil {
+			return nil, err
+		}
 	}
 
-	return answers
+	return answers, nil
 }
 
-func askFinalConfigQuestions() *configAnswers {
+func askFinalConfigQuestions(orchestration string, scm string, needs []string) (*configAnswers, error) {
 	answers := &configAnswers{}
-	answers.AuditURL = "http://gateway.openfaas:8080/function/echo"
+	answers.AuditURL = defaultAuditURL
 	answers.OFVersion = defaultVersion
+	if min := minVersionForFields(needs); min != "" && compareVersions(defaultVersion, min) < 0 {
+		answers.OFVersion = min
+	}
+
+	// ofc version
+	var versionQuestion = &survey.Input{
+		Message: "Enter the version of OpenFaaS Cloud to use:",
+		Default: answers.OFVersion,
+		Help:    "See available versions here: https://github.com/openfaas/openfaas-cloud/releases/",
+	}
+
+	if err := askOne(ofcVersionKey, versionQuestion, &answers.OFVersion, validateVersionFor(needs)); err != nil {
+		return nil, err
+	}
 
-	var customAuditQuestion = &survey.Confirm{Message: "Would you like to use a custom audit trail URL (ie post to Slack)?"}
+	// only ask the questions supported by the chosen version
+	versionSchema, err := schemaFor(answers.OFVersion)
+	if err != nil {
+		return nil, err
+	}
 
-	customAudit := false
-	survey.AskOne(customAuditQuestion, &customAudit, nil)
+	// audit trail
+	auditTo := auditToEcho
+	var auditToQuestion = &survey.Select{
+		Message: "Where should the audit trail be posted to?",
+		Options: []string{auditToEcho, auditToSlack, auditToHTTP},
+		Default: auditToEcho,
+		Help:    "echo logs each event with the built-in echo function, slack posts them to a Slack incoming webhook and http posts them to any other endpoint",
+	}
 
-	if customAudit {
+	if err := askOne(auditToKey, auditToQuestion, &auditTo, nil); err != nil {
+		return nil, err
+	}
+
+	switch auditTo {
+	case auditToSlack:
+		var slackURLQuestion = &survey.Input{
+			Message: "Enter the Slack incoming webhook URL:",
+			Help:    "Create a webhook at https://api.slack.com/messaging/webhooks, eg: https://hooks.slack.com/services/T000/B000/XXXX",
+		}
+		if err := askOne(auditURLKey, slackURLQuestion, &answers.AuditURL, validateSlackWebhook); err != nil {
+			return nil, err
+		}
+	case auditToHTTP:
 		var auditURLQuestion = &survey.Input{Message: "URL to post audit trail message to:"}
-		survey.AskOne(auditURLQuestion, &answers.AuditURL, nil)
+		if err := askOne(auditURLKey, auditURLQuestion, &answers.AuditURL, validateAuditURL); err != nil {
+			return nil, err
+		}
 	}
 
 	// customers
-	var custURLQuestion = &survey.Input{
-		Message: "URL of the customers access control list:",
-		Help:    "The raw text file, or Github raw URL of allowed users. This must be a public endpoint",
+	customersFrom := customersFromURL
+	if versionSchema.supports("customers_secret") {
+		var customersSourceQuestion = &survey.Select{
+			Message: "Where should the customers access control list be read from?",
+			Options: []string{customersFromURL, customersFromSecret},
+			Default: customersFromURL,
+			Help:    "A url must be public. A secret keeps the list private, reading it from a local file",
+		}
+		if err := askOne(customersSourceKey, customersSourceQuestion, &customersFrom, nil); err != nil {
+			return nil, err
+		}
 	}
 
-	survey.AskOne(custURLQuestion, &answers.CustomersURL, nil)
+	if customersFrom == customersFromSecret {
+		answers.CustomersSecret = true
+		file, err := askCustomersFile(scm)
+		if err != nil {
+			return nil, err
+		}
+		answers.CustomersFile = file
+	} else {
+		var custURLQuestion = &survey.Input{
+			Message: "URL of the customers access control list:",
+			Help:    "The raw text file, or Github raw URL of allowed users. This must be a public endpoint",
+		}
+
+		if err := askOne(customersURLKey, custURLQuestion, &answers.CustomersURL, validateCustomersURL); err != nil {
+			return nil, err
+		}
+	}
 
 	// dockerfile
 	var dockerfileQuestion = &survey.Confirm{
@@ -469,7 +742,11 @@ func askFinalConfigQuestions() *configAnswers {
 		Help:    "This will allow templates built using dockerfile to be deployed which will allow ANY workload to be built and run. Use with caution",
 	}
 
-	survey.AskOne(dockerfileQuestion, &answers.UseDockerfile, nil)
+	if versionSchema.supports("enable_dockerfile_lang") {
+		if err := askOne(enableDockerfileKey, dockerfileQuestion, &answers.UseDockerfile, nil); err != nil {
+			return nil, err
+		}
+	}
 
 	// scale-zero
 	var scaleZeroQuestion = &survey.Confirm{
@@ -477,31 +754,66 @@ func askFinalConfigQuestions() *configAnswers {
 		Help:    "With this enabled, all functions will scale to zero. To turn off, add a label 'com.openfaas.scale.zero: false'",
 	}
 
-	survey.AskOne(scaleZeroQuestion, &answers.ScaleZero, nil)
-
-	// ofc version
-	var versionQuestion = &survey.Input{
-		Message: fmt.Sprintf("Enter the version of OpenFaaS Cloud to use (blank for default: %s)", defaultVersion),
-		Help:    "See available versions here: https://github.com/openfaas/openfaas-cloud/releases/",
+	if versionSchema.supports("scale_to_zero") {
+		if err := askOne(scaleToZeroKey, scaleZeroQuestion, &answers.ScaleZero, nil); err != nil {
+			return nil, err
+		}
 	}
 
-	survey.AskOne(versionQuestion, &answers.OFVersion, nil)
-
 	// network policies
 	var netPoliciesQuestion = &survey.Confirm{
 		Message: "Would you like to enable network policies (restrict functions from calling the openfaas namespace, recommended)",
 		Help:    "Prevents functions from talkking to the openfaas namespace, and to each other. Use the ingress address for the gateway or external IP instead",
 	}
 
-	survey.AskOne(netPoliciesQuestion, &answers.NetworkPolicies, nil)
+	if versionSchema.supports("network_policies") && appliesTo(orchestration, "network_policies") {
+		if err := askOne(networkPoliciesKey, netPoliciesQuestion, &answers.NetworkPolicies, nil); err != nil {
+			return nil, err
+		}
+	}
 
 	var ingressQuestion = &survey.Select{
 		Message: "Choose which type of ingress to use:",
 		Options: []string{"loadbalancer", "host"},
 	}
 
-	survey.AskOne(ingressQuestion, &answers.Ingress, nil)
-	// TODO: custom templates
+	if appliesTo(orchestration, "ingress") {
+		if err := askOne(ingressKey, ingressQuestion, &answers.Ingress, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	// build branch
+	if versionSchema.supports("build_branch") {
+		var buildBranchQuestion = &survey.Input{
+			Message: "Enter the branch to build functions from:",
+			Default: defaultBuildBranch,
+		}
 
-	return answers
+		if err := askOne(buildBranchKey, buildBranchQuestion, &answers.BuildBranch, survey.Required); err != nil {
+			return nil, err
+		}
+	}
+
+	// custom templates
+	repos := ""
+	var templatesQuestion = &survey.Input{
+		Message: "Enter any custom template repositories (comma separated):",
+		Help:    "The https URLs of git repositories with templates to build functions from. Add #<branch> or #<tag> to use a branch or tag other than master",
+	}
+
+	if err := askOne(customTemplatesKey, templatesQuestion, &repos, validateCustomTemplates); err != nil {
+		return nil, err
+	}
+
+	templates, duplicates, _ := customTemplates(splitList(repos))
+	for _, d := range duplicates {
+		fmt.Printf("%s is listed more than once, it will only be added once\n", d)
+	}
+	for _, w := range dockerfileTemplateWarnings(templates, answers.UseDockerfile) {
+		fmt.Println("Warning: " + w)
+	}
+	answers.CustomTemplates = templates
+
+	return answers, nil
 }
@@ -0,0 +1,161 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+var (
+	githubAppManifestFile = "github-app-manifest.json"
+	githubAppPageFile     = "github-app.html"
+	githubAppNameLimit    = 34
+	githubWebhookPath     = "github-event"
+)
+
+// githubAppPermissions are the permissions OpenFaaS Cloud needs to build repositories and report their status
+var githubAppPermissions = map[string]string{
+	"checks":   "write",
+	"contents": "read",
+	"metadata": "read",
+	"statuses": "write",
+}
+
+var githubAppEvents = []string{"push"}
+
+// githubNamePattern matches GitHub user and organisation names
+var githubNamePattern = regexp.MustCompile(`^[A-Za-z0-9](-?[A-Za-z0-9])*$`)
+
+var githubAppPage = template.Must(template.New("github-app").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Create the GitHub App for OpenFaaS Cloud</title>
+</head>
+<body>
+  <h1>Create the GitHub App for OpenFaaS Cloud at {{.RootDomain}}</h1>
+  <p>This page was generated by ofc-wizard. The button sends the app settings below to GitHub, which asks you to confirm the name before creating the app.</p>
+  <form action="{{.Action}}" method="post">
+    <input type="hidden" name="manifest" value="{{.Manifest}}">
+    <button type="submit">Create GitHub App</button>
+  </form>
+  <h2>After creating the app</h2>
+  <p>GitHub then opens {{.RedirectURL}}, which does not load until OpenFaaS Cloud is installed. The app has already been created.</p>
+  <ol>
+    <li>Note the App ID, and enter it as the Github App ID when running ofc-wizard again</li>
+    <li>Generate a private key, and enter the path of the downloaded file when asked for it</li>
+    <li>Set the webhook secret to the value of the github-webhook-secret</li>
+    <li>Install the app on the users or organisations whose repositories will be built</li>
+  </ol>
+  <h2>App settings</h2>
+  <pre>{{.Manifest}}</pre>
+</body>
+</html>
+`))
+
+// githubAppName returns a name for the GitHub App, which must be unique on GitHub and 34 characters or fewer
+func githubAppName(rootDomain string) string {
+	name := "ofc-" + strings.Replace(rootDomain, ".", "-", -1)
+	if len(name) > githubAppNameLimit {
+		name = strings.TrimRight(name[:githubAppNameLimit], "-")
+	}
+	return name
+}
+
+// githubAppManifest returns the manifest of the GitHub App for the init.yml
+func githubAppManifest(yml types.InitYaml) types.GitHubAppManifest {
+	manifest := types.GitHubAppManifest{
+		Name:        githubAppName(yml.RootDomain),
+		URL:         systemURL(yml, ""),
+		Description: fmt.Sprintf("Builds and deploys functions to OpenFaaS Cloud at %s", yml.RootDomain),
+		HookAttributes: types.HookAttributes{
+			URL:    systemURL(yml, githubWebhookPath),
+			Active: true,
+		},
+		// GitHub returns to the dashboard once the app is created
+		RedirectURL:        systemURL(yml, ""),
+		Public:             false,
+		DefaultPermissions: githubAppPermissions,
+		DefaultEvents:      githubAppEvents,
+	}
+
+	if yml.EnableOAuth {
+		manifest.CallbackURLs = []string{authURL(yml)}
+	}
+	return manifest
+}
+
+// githubAppNewURL returns the page the manifest is posted to, for a user account or an organisation
+func githubAppNewURL(org string) string {
+	if org == "" {
+		return "https://github.com/settings/apps/new"
+	}
+	return fmt.Sprintf("https://github.com/organizations/%s/settings/apps/new", org)
+}
+
+// renderGitHubApp returns the manifest as JSON, and the page which posts it to GitHub
+func renderGitHubApp(yml types.InitYaml, org string) ([]byte, []byte, error) {
+	if org != "" && (len(org) > 39 || !githubNamePattern.MatchString(org)) {
+		return nil, nil, fmt.Errorf("%q is not a valid Github organisation name", org)
+	}
+
+	manifest, err := json.MarshalIndent(githubAppManifest(yml), "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := &bytes.Buffer{}
+	err = githubAppPage.Execute(page, struct {
+		RootDomain  string
+		Action      string
+		RedirectURL string
+		Manifest    string
+	}{
+		RootDomain:  yml.RootDomain,
+		Action:      githubAppNewURL(org),
+		RedirectURL: systemURL(yml, ""),
+		Manifest:    string(manifest),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return append(manifest, '\n'), page.Bytes(), nil
+}
+
+// writeGitHubApp writes the GitHub App manifest and the page to create the app from it
+func writeGitHubApp(yml types.InitYaml, org string) error {
+	manifest, page, err := renderGitHubApp(yml, org)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(githubAppManifestFile, manifest, 0644); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(githubAppPageFile, page, 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %s and %s, open %s in a browser to create the GitHub App\n", githubAppManifestFile, githubAppPageFile, githubAppPageFile)
+	return nil
+}
+
+// ExportGitHubApp writes the GitHub App manifest for the init.yml at the path, along with
+// a page which creates the app for the user, or the organisation when one is given
+func ExportGitHubApp(path string, org string) {
+	yml := LoadInitFileFrom(path)
+
+	if err := checkRootDomain(yml.RootDomain); err != nil {
+		exitWithError(err)
+	}
+	if err := writeGitHubApp(*yml, org); err != nil {
+		exitWithError(err)
+	}
+}
@@ -0,0 +1,110 @@
+package actions
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// checkGolden compares the output with the file in testdata, or rewrites it when -update is given
+func checkGolden(t *testing.T, name string, got []byte) {
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s does not match, want:\n%s\ngot:\n%s", name, want, got)
+	}
+}
+
+func Test_renderGitHubApp_Golden(t *testing.T) {
+	cases := []struct {
+		name string
+		yml  types.InitYaml
+		org  string
+	}{
+		{
+			name: "github-app-tls-oauth-org",
+			yml:  types.InitYaml{RootDomain: "example.com", TLS: true, EnableOAuth: true},
+			org:  "openfaas",
+		},
+		{
+			name: "github-app-no-tls",
+			yml:  types.InitYaml{RootDomain: "example.com"},
+		},
+	}
+
+	for _, c := range cases {
+		manifest, page, err := renderGitHubApp(c.yml, c.org)
+		if err != nil {
+			t.Fatalf("%s: %s", c.name, err)
+		}
+		checkGolden(t, c.name+".json", manifest)
+		checkGolden(t, c.name+".html", page)
+
+		again, _, _ := renderGitHubApp(c.yml, c.org)
+		if !bytes.Equal(manifest, again) {
+			t.Errorf("%s: want the same manifest each time", c.name)
+		}
+	}
+}
+
+func Test_githubAppManifest_URLs(t *testing.T) {
+	manifest := githubAppManifest(types.InitYaml{RootDomain: "example.com", TLS: true, EnableOAuth: true})
+	if manifest.URL != "https://system.example.com/" {
+		t.Errorf("want the https system URL, got %s", manifest.URL)
+	}
+	if manifest.HookAttributes.URL != "https://system.example.com/github-event" {
+		t.Errorf("want the https webhook URL, got %s", manifest.HookAttributes.URL)
+	}
+	if len(manifest.CallbackURLs) != 1 || manifest.CallbackURLs[0] != "https://auth.system.example.com/" {
+		t.Errorf("want the OAuth callback URL, got %v", manifest.CallbackURLs)
+	}
+
+	manifest = githubAppManifest(types.InitYaml{RootDomain: "example.com"})
+	if manifest.HookAttributes.URL != "http://system.example.com/github-event" {
+		t.Errorf("want the http webhook URL, got %s", manifest.HookAttributes.URL)
+	}
+	if len(manifest.CallbackURLs) != 0 {
+		t.Errorf("want no callback URLs without OAuth, got %v", manifest.CallbackURLs)
+	}
+}
+
+func Test_githubAppNewURL(t *testing.T) {
+	if url := githubAppNewURL(""); url != "https://github.com/settings/apps/new" {
+		t.Errorf("want the user URL, got %s", url)
+	}
+	if url := githubAppNewURL("openfaas"); url != "https://github.com/organizations/openfaas/settings/apps/new" {
+		t.Errorf("want the organisation URL, got %s", url)
+	}
+}
+
+func Test_renderGitHubApp_InvalidOrg(t *testing.T) {
+	for _, org := range []string{"-openfaas", "open faas", "openfaas/cloud"} {
+		if _, _, err := renderGitHubApp(types.InitYaml{RootDomain: "example.com"}, org); err == nil {
+			t.Errorf("want an error for %q", org)
+		}
+	}
+}
+
+func Test_githubAppName(t *testing.T) {
+	if name := githubAppName("example.com"); name != "ofc-example-com" {
+		t.Errorf("want ofc-example-com, got %s", name)
+	}
+	if name := githubAppName("a-very-long-subdomain.of-an-example.com"); len(name) > githubAppNameLimit {
+		t.Errorf("want at most %d characters, got %s", githubAppNameLimit, name)
+	}
+}
@@ -0,0 +1,122 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/burtonr/ofc-wizard/types"
+	"gopkg.in/AlecAivazis/survey.v1"
+)
+
+// parseGitLabURL parses the URL of a GitLab instance, which must be an absolute http or https URL
+func parseGitLabURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid URL: %s", raw, err.Error())
+	}
+
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return nil, fmt.Errorf("%q must start with https:// (eg: https://gitlab.example.com/)", raw)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("%q does not include a host name", raw)
+	}
+	if u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return nil, fmt.Errorf("%q must not include a query, fragment or login", raw)
+	}
+
+	// the API and OAuth paths are added to the instance URL by ofc-bootstrap
+	if path := strings.TrimRight(u.Path, "/"); strings.HasSuffix(path, "/api/v4") || strings.HasSuffix(path, "/api/v3") {
+		return nil, fmt.Errorf("%q is the GitLab API URL, use the URL of the instance (eg: %s://%s%s/)", raw, u.Scheme, u.Host, path[:len(path)-len("/api/v4")])
+	}
+
+	u.Host = strings.ToLower(u.Host)
+	return u, nil
+}
+
+// validateGitLabURL is a survey validator for the GitLab instance and OAuth provider URLs
+func validateGitLabURL(val interface{}) error {
+	str, ok := val.(string)
+	if !ok {
+		return errors.New("The URL must be a string")
+	}
+	_, err := parseGitLabURL(str)
+	return err
+}
+
+// normaliseGitLabInstance returns the GitLab instance URL with the trailing slash ofc-bootstrap expects
+func normaliseGitLabInstance(raw string) string {
+	u, err := parseGitLabURL(raw)
+	if err != nil {
+		return raw
+	}
+	return u.Scheme + "://" + u.Host + strings.TrimRight(u.Path, "/") + "/"
+}
+
+// gitlabOAuthBaseURL returns the OAuth provider base URL for the GitLab instance, without a trailing slash
+func gitlabOAuthBaseURL(instance string) string {
+	return strings.TrimSuffix(normaliseGitLabInstance(instance), "/")
+}
+
+// gitlabURLWarning returns a warning when the GitLab instance is not served over https
+func gitlabURLWarning(instance string) string {
+	if u, err := parseGitLabURL(instance); err == nil && u.Scheme == "http" {
+		return fmt.Sprintf("%s does not use https, the webhook secret and OAuth tokens will be sent in plain text", instance)
+	}
+	return ""
+}
+
+// checkGitLabBaseURL returns an error when the OAuth provider base URL is not the GitLab instance
+func checkGitLabBaseURL(instance string, baseURL string) error {
+	if _, err := parseGitLabURL(baseURL); err != nil {
+		return err
+	}
+
+	if gitlabOAuthBaseURL(baseURL) != gitlabOAuthBaseURL(instance) {
+		return fmt.Errorf("The OAuth provider base URL %s must be the GitLab instance %s", baseURL, gitlabOAuthBaseURL(instance))
+	}
+	return nil
+}
+
+// gitlabBaseURLQuestion returns the OAuth provider base URL question, defaulting to and
+// validated against the GitLab instance
+func gitlabBaseURLQuestion(instance string) *survey.Question {
+	return &survey.Question{
+		Name: "BaseURL",
+		Prompt: &survey.Input{
+			Message: "Enter your OAuth Provider Base URL:",
+			Default: gitlabOAuthBaseURL(instance),
+			Help:    "The URL of the GitLab instance the OAuth application was created in",
+		},
+		Validate: func(val interface{}) error {
+			if str, ok := val.(string); ok && instance != "" {
+				return checkGitLabBaseURL(instance, str)
+			}
+			return validateGitLabURL(val)
+		},
+	}
+}
+
+// checkGitLab returns an error for each problem with the GitLab settings of the init.yml
+func checkGitLab(yml types.InitYaml) []error {
+	errs := []error{}
+	if yml.SCM != gitlab {
+		return errs
+	}
+
+	if _, err := parseGitLabURL(yml.GitLab.GitLabInstance); err != nil {
+		return append(errs, fmt.Errorf("gitlab_instance: %s", err.Error()))
+	}
+	if !strings.HasSuffix(yml.GitLab.GitLabInstance, "/") {
+		errs = append(errs, fmt.Errorf("gitlab_instance must end with a '/', eg: %s", normaliseGitLabInstance(yml.GitLab.GitLabInstance)))
+	}
+
+	if yml.EnableOAuth {
+		if err := checkGitLabBaseURL(yml.GitLab.GitLabInstance, yml.OAuth.OAuthProviderBaseURL); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
@@ -30,7 +30,12 @@ func CreateInitFile() *types.InitYaml {
 // LoadInitFile marshalls the values from the init.yml file in the local directory
 func LoadInitFile() *types.InitYaml {
 	fmt.Println("Loading existing init.yml file")
-	yamlBytes, yamlErr := ioutil.ReadFile("init.yml")
+	return LoadInitFileFrom("init.yml")
+}
+
+// LoadInitFileFrom marshalls the values from the init.yml file at the given path
+func LoadInitFileFrom(path string) *types.InitYaml {
+	yamlBytes, yamlErr := ioutil.ReadFile(path)
 	if yamlErr != nil {
 		fmt.Fprintf(os.Stderr, "-yaml file gave error: %s\n", yamlErr.Error())
 		os.Exit(1)
@@ -43,10 +48,47 @@ func LoadInitFile() *types.InitYaml {
 		os.Exit(1)
 	}
 
+	useLegacyFields(&init, path)
+
+	if decryptErr := decryptSecrets(init.Secrets); decryptErr != nil {
+		fmt.Fprintf(os.Stderr, "-yaml file gave error: %s\n", decryptErr.Error())
+		os.Exit(1)
+	}
+	if decryptErr := decryptSlackURL(&init.Slack); decryptErr != nil {
+		fmt.Fprintf(os.Stderr, "-yaml file gave error: %s\n", decryptErr.Error())
+		os.Exit(1)
+	}
+
 	return &init
 }
 
+// useLegacyFields reads the fields written under their old names by older versions of the
+// wizard, warning that the file should be migrated
+func useLegacyFields(init *types.InitYaml, path string) {
+	if init.LegacyCustomersURL == "" {
+		return
+	}
+
+	if init.CustomersURL == "" {
+		init.CustomersURL = init.LegacyCustomersURL
+	}
+	init.LegacyCustomersURL = ""
+	fmt.Fprintf(os.Stderr, "Warning: %s uses the deprecated cusomter_url, run ofc-wizard migrate to rename it to customers_url\n", path)
+}
+
 // WriteInitFile writes the values to the init.yml file in the local directory
 func WriteInitFile(yml types.InitYaml) {
 	fmt.Println("Writing the file")
+	yml.Secrets = secretsToWrite(yml.Secrets)
+	yml.Slack.URL = slackURLToWrite(yml.Slack.URL)
+	yamlBytes, marshalErr := yaml.Marshal(yml)
+	if marshalErr != nil {
+		fmt.Fprintf(os.Stderr, "-yaml file gave error: %s\n", marshalErr.Error())
+		os.Exit(1)
+	}
+
+	if wErr := ioutil.WriteFile("init.yml", yamlBytes, 0644); wErr != nil {
+		fmt.Printf("Trouble writing init.yml file: %s\n", wErr.Error())
+		panic(wErr)
+	}
 }
@@ -0,0 +1,38 @@
+package actions
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_LoadInitFileFrom_LegacyCustomersURL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ofc-wizard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"legacy", "cusomter_url: https://example.com/legacy\n", "https://example.com/legacy"},
+		{"both", "cusomter_url: https://example.com/legacy\ncustomers_url: https://example.com/CUSTOMERS\n", "https://example.com/CUSTOMERS"},
+		{"current", "customers_url: https://example.com/CUSTOMERS\n", "https://example.com/CUSTOMERS"},
+	}
+
+	for _, c := range cases {
+		path := filepath.Join(dir, c.name+".yml")
+		if err := ioutil.WriteFile(path, []byte(c.yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		yml := LoadInitFileFrom(path)
+		if yml.CustomersURL != c.want || yml.LegacyCustomersURL != "" {
+			t.Errorf("%s: want customers_url %s, got %q (legacy %q)", c.name, c.want, yml.CustomersURL, yml.LegacyCustomersURL)
+		}
+	}
+}
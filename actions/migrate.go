@@ -0,0 +1,341 @@
+package actions
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/AlecAivazis/survey.v1"
+	"gopkg.in/yaml.v2"
+)
+
+// migration is a single step in upgrading an init.yml between ofc-bootstrap versions
+type migration struct {
+	// Schema is the schema version that introduced the change. Steps without a schema are
+	// applied to every file, and leave it unchanged when it already has the change
+	Schema      string
+	Description string
+	Apply       func(doc yaml.MapSlice) (yaml.MapSlice, error)
+}
+
+// migrations lists every migration step, in the order they must be applied
+var migrations = []migration{
+	{
+		Description: "Rename cusomter_url to customers_url",
+		Apply: func(doc yaml.MapSlice) (yaml.MapSlice, error) {
+			return renameKey(doc, "cusomter_url", "customers_url"), nil
+		},
+	},
+	{
+		Description: "Add the Dockerfile template and scale-to-zero settings",
+		Apply: func(doc yaml.MapSlice) (yaml.MapSlice, error) {
+			doc = setDefaultKey(doc, "enable_dockerfile_lang", false)
+			return setDefaultKey(doc, "scale_to_zero", false), nil
+		},
+	},
+	{
+		Description: "Add the payload-secret used to sign requests between functions",
+		Apply: func(doc yaml.MapSlice) (yaml.MapSlice, error) {
+			if hasSecret(doc, "payload-secret") {
+				return doc, nil
+			}
+
+			value := ""
+			question := &survey.Password{Message: "Enter a value for the new payload-secret (leave blank for a random value):"}
+			if err := askMigrationValue(payloadSecretKey, question, &value, nil); err != nil {
+				return doc, err
+			}
+
+			if value == "" {
+				generated, err := generateSecretValue()
+				if err != nil {
+					return doc, err
+				}
+				if value, err = storeGeneratedValue("payload-secret", "payload-secret", generated); err != nil {
+					return doc, err
+				}
+			}
+
+			return addLiteralSecret(doc, "payload-secret", value, "default", functionsNamespace)
+		},
+	},
+	{
+		Description: "Add the network policies setting",
+		Apply: func(doc yaml.MapSlice) (yaml.MapSlice, error) {
+			return setDefaultKey(doc, "network_policies", false), nil
+		},
+	},
+	{
+		Description: "Add the branch to build from",
+		Apply: func(doc yaml.MapSlice) (yaml.MapSlice, error) {
+			if v, ok := getKey(doc, "build_branch"); ok && !isZeroValue(v) {
+				return doc, nil
+			}
+
+			branch := defaultBuildBranch
+			question := &survey.Input{Message: "Enter the branch to build functions from:", Default: defaultBuildBranch}
+			if err := askMigrationValue(buildBranchKey, question, &branch, survey.Required); err != nil {
+				return doc, err
+			}
+			return setKey(doc, "build_branch", branch), nil
+		},
+	},
+	{
+		Description: "Add the setting to read the customers list from a secret",
+		Apply: func(doc yaml.MapSlice) (yaml.MapSlice, error) {
+			return setDefaultKey(doc, "customers_secret", false), nil
+		},
+	},
+}
+
+// migrateAssumeYes is set when the changes are written without confirmation, so new values
+// which are not preset take their default instead of being asked for
+var migrateAssumeYes bool
+
+// askMigrationValue asks for a new value needed by a migration step, unless it is preset or
+// the changes are written without confirmation, when the response keeps its default
+func askMigrationValue(key string, prompt survey.Prompt, response interface{}, validate survey.Validator) error {
+	if migrateAssumeYes && !isPreset(key) {
+		return nil
+	}
+	return askOne(key, prompt, response, validate)
+}
+
+// MigrateInitFile upgrades the init.yml at the path to the given OpenFaaS Cloud version,
+// printing the changes and asking for confirmation before writing them
+func MigrateInitFile(path string, toVersion string, assumeYes bool) {
+	migrateAssumeYes = assumeYes
+	target, err := schemaFor(toVersion)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	file := loadYamlDoc(path)
+	doc := file.Doc
+	if from := docSchema(doc); compareVersions(from.Version, target.Version) > 0 {
+		fmt.Fprintf(os.Stderr, "%s uses schema version %s, migrating to the older schema %s is not supported\n", path, from.Version, target.Version)
+		os.Exit(1)
+	}
+
+	before := flattenDoc(doc)
+	migrated, err := migrateDoc(doc, target, toVersion)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Migration failed: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	changes := diffFields(before, flattenDoc(migrated))
+	fmt.Println()
+	printChanges(changes)
+	if len(changes) == 0 {
+		return
+	}
+
+	if !assumeYes {
+		confirmed := false
+		if err := survey.AskOne(&survey.Confirm{Message: fmt.Sprintf("Write the changes to %s?", path)}, &confirmed, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Migration cancelled: %s\n", err.Error())
+			os.Exit(1)
+		}
+		if !confirmed {
+			return
+		}
+	}
+
+	writeYamlDoc(path, file, migrated)
+}
+
+// migrateDoc returns a copy of the document with each migration step from its schema up to the
+// target schema applied, and the OpenFaaS Cloud version set
+func migrateDoc(doc yaml.MapSlice, target schema, toVersion string) (yaml.MapSlice, error) {
+	from := docSchema(doc)
+	migrated := append(yaml.MapSlice{}, doc...)
+
+	for _, m := range migrations {
+		if m.Schema != "" && (compareVersions(m.Schema, from.Version) <= 0 || compareVersions(m.Schema, target.Version) > 0) {
+			continue
+		}
+
+		fmt.Println(m.Description)
+		var err error
+		if migrated, err = m.Apply(migrated); err != nil {
+			return nil, err
+		}
+	}
+
+	migrated = setKey(migrated, "openfaas_cloud_version", toVersion)
+	return setKey(migrated, schemaMarkerField, target.Version), nil
+}
+
+// docSchema returns the schema the document was written for, from its schema marker or
+// OpenFaaS Cloud version, falling back to the oldest schema
+func docSchema(doc yaml.MapSlice) schema {
+	if v, ok := getKey(doc, schemaMarkerField); ok {
+		for _, s := range schemas {
+			if s.Version == fmt.Sprint(v) {
+				return s
+			}
+		}
+	}
+
+	if v, ok := getKey(doc, "openfaas_cloud_version"); ok {
+		if s, err := schemaFor(fmt.Sprint(v)); err == nil {
+			return s
+		}
+	}
+	return schemas[0]
+}
+
+// yamlFile is a yaml document along with the source it was read from, so it can be written
+// back by editing only the values which changed
+type yamlFile struct {
+	Source []byte
+	Doc    yaml.MapSlice
+}
+
+// loadYamlDoc reads the yaml file at the path, keeping the order and any fields unknown to the wizard
+func loadYamlDoc(path string) yamlFile {
+	yamlBytes, yamlErr := ioutil.ReadFile(path)
+	if yamlErr != nil {
+		fmt.Fprintf(os.Stderr, "-yaml file gave error: %s\n", yamlErr.Error())
+		os.Exit(1)
+	}
+
+	doc := yaml.MapSlice{}
+	if unmarshalErr := yaml.Unmarshal(yamlBytes, &doc); unmarshalErr != nil {
+		fmt.Fprintf(os.Stderr, "-yaml file gave error: %s\n", unmarshalErr.Error())
+		os.Exit(1)
+	}
+	return yamlFile{Source: yamlBytes, Doc: doc}
+}
+
+// writeYamlDoc writes the yaml document to the file at the path, editing the source of the
+// file it was read from so its comments and layout are kept
+func writeYamlDoc(path string, file yamlFile, doc yaml.MapSlice) {
+	fmt.Printf("Writing %s\n", path)
+	yamlBytes := marshalYamlDoc(file, doc)
+
+	if wErr := ioutil.WriteFile(path, yamlBytes, 0644); wErr != nil {
+		fmt.Printf("Trouble writing %s file: %s\n", path, wErr.Error())
+		panic(wErr)
+	}
+}
+
+// marshalYamlDoc returns the source of the file edited to hold the values of the document
+func marshalYamlDoc(file yamlFile, doc yaml.MapSlice) []byte {
+	yamlBytes, marshalErr := editYaml(file.Source, doc)
+	if marshalErr != nil {
+		fmt.Fprintf(os.Stderr, "-yaml file gave error: %s\n", marshalErr.Error())
+		os.Exit(1)
+	}
+	return yamlBytes
+}
+
+func getKey(doc yaml.MapSlice, key string) (interface{}, bool) {
+	for _, item := range doc {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+// setKey sets the value of the key, adding it to the end of the document when missing
+func setKey(doc yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for i, item := range doc {
+		if item.Key == key {
+			doc[i].Value = value
+			return doc
+		}
+	}
+	return append(doc, yaml.MapItem{Key: key, Value: value})
+}
+
+// setDefaultKey adds the key with the value when it is missing
+func setDefaultKey(doc yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	if _, ok := getKey(doc, key); ok {
+		return doc
+	}
+	return setKey(doc, key, value)
+}
+
+// renameKey renames the key, keeping its position. An existing value of the new key wins
+func renameKey(doc yaml.MapSlice, from string, to string) yaml.MapSlice {
+	_, exists := getKey(doc, to)
+
+	renamed := yaml.MapSlice{}
+	for _, item := range doc {
+		if item.Key == from {
+			if exists {
+				continue
+			}
+			item.Key = to
+		}
+		renamed = append(renamed, item)
+	}
+	return renamed
+}
+
+func hasSecret(doc yaml.MapSlice, name string) bool {
+	secrets, _ := getKey(doc, "secrets")
+	list, _ := secrets.([]interface{})
+
+	for _, s := range list {
+		if itemID(s, -1) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasEncryptedValues reports whether any literal value of the document's secrets, or its
+// Slack webhook URL, is encrypted
+func hasEncryptedValues(doc yaml.MapSlice) bool {
+	secrets, _ := getKey(doc, "secrets")
+	list, _ := secrets.([]interface{})
+
+	for _, s := range list {
+		secret, _ := s.(yaml.MapSlice)
+		literals, _ := getKey(secret, "literals")
+		items, _ := literals.([]interface{})
+
+		for _, l := range items {
+			literal, _ := l.(yaml.MapSlice)
+			if v, ok := getKey(literal, "value"); ok && isEncrypted(fmt.Sprint(v)) {
+				return true
+			}
+		}
+	}
+
+	slack, _ := getKey(doc, "slack")
+	fields, _ := slack.(yaml.MapSlice)
+	url, _ := getKey(fields, "url")
+	return isEncrypted(fmt.Sprint(url))
+}
+
+// addLiteralSecret appends a secret with a single literal of the same name. The value is
+// encrypted when the document's other values are
+func addLiteralSecret(doc yaml.MapSlice, name string, value string, filter string, namespace string) (yaml.MapSlice, error) {
+	secrets, _ := getKey(doc, "secrets")
+	list, _ := secrets.([]interface{})
+
+	if value != "" && !isVaultRef(value) && hasEncryptedValues(doc) {
+		encrypted, err := encryptValue(value)
+		if err != nil {
+			return doc, err
+		}
+		value = encrypted
+	}
+
+	secret := yaml.MapSlice{
+		{Key: "name", Value: name},
+		{Key: "literals", Value: []interface{}{
+			yaml.MapSlice{{Key: "name", Value: name}, {Key: "value", Value: value}},
+		}},
+		{Key: "filters", Value: []interface{}{filter}},
+		{Key: "namespace", Value: namespace},
+	}
+
+	return setKey(doc, "secrets", append(list, secret)), nil
+}
@@ -0,0 +1,196 @@
+package actions
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+// v1Doc is an init.yml written by the first versions of the wizard
+func v1Doc() yaml.MapSlice {
+	return yaml.MapSlice{
+		{Key: "orchestration", Value: "kubernetes"},
+		{Key: "secrets", Value: []interface{}{
+			yaml.MapSlice{
+				{Key: "name", Value: "s3-secret-key"},
+				{Key: "literals", Value: []interface{}{
+					yaml.MapSlice{{Key: "name", Value: "s3-secret-key"}, {Key: "value", Value: "secret"}},
+				}},
+				{Key: "filters", Value: []interface{}{"default"}},
+				{Key: "namespace", Value: "openfaas-fn"},
+			},
+		}},
+		{Key: "root_domain", Value: "example.com"},
+		{Key: "cusomter_url", Value: "https://example.com/CUSTOMERS"},
+		{Key: "openfaas_cloud_version", Value: "0.9.7"},
+	}
+}
+
+func Test_migrateDoc(t *testing.T) {
+	migrateAssumeYes = true
+	defer func() { migrateAssumeYes = false }()
+
+	for _, version := range []string{"0.9.7", "0.10.0", "0.12.0"} {
+		target, err := schemaFor(version)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		migrated, err := migrateDoc(v1Doc(), target, version)
+		if err != nil {
+			t.Fatalf("%s: %s", version, err)
+		}
+
+		keys := []interface{}{}
+		for _, item := range migrated {
+			keys = append(keys, item.Key)
+		}
+		want := []interface{}{
+			"orchestration", "secrets", "root_domain", "customers_url", "openfaas_cloud_version",
+			"enable_dockerfile_lang", "scale_to_zero", "network_policies", "build_branch",
+			"customers_secret", schemaMarkerField,
+		}
+		if !reflect.DeepEqual(keys, want) {
+			t.Errorf("%s: want keys %v, got %v", version, want, keys)
+		}
+
+		if v, _ := getKey(migrated, "openfaas_cloud_version"); v != version {
+			t.Errorf("%s: want the version set, got %v", version, v)
+		}
+		if v, _ := getKey(migrated, "build_branch"); v != defaultBuildBranch {
+			t.Errorf("%s: want the default branch, got %v", version, v)
+		}
+		if !hasSecret(migrated, "payload-secret") {
+			t.Errorf("%s: want the payload-secret added", version)
+		}
+	}
+}
+
+func Test_migrateDoc_Presets(t *testing.T) {
+	migrateAssumeYes = true
+	defer func() { migrateAssumeYes = false }()
+	os.Setenv(envName(buildBranchKey), "main")
+	defer os.Unsetenv(envName(buildBranchKey))
+
+	migrated, err := migrateDoc(v1Doc(), schemas[0], defaultVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := getKey(migrated, "build_branch"); v != "main" {
+		t.Errorf("want the branch from the environment, got %v", v)
+	}
+}
+
+func Test_renameKey(t *testing.T) {
+	doc := yaml.MapSlice{{Key: "a", Value: 1}, {Key: "old", Value: 2}, {Key: "c", Value: 3}}
+	want := yaml.MapSlice{{Key: "a", Value: 1}, {Key: "new", Value: 2}, {Key: "c", Value: 3}}
+	if got := renameKey(doc, "old", "new"); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	doc = yaml.MapSlice{{Key: "old", Value: 1}, {Key: "new", Value: 2}}
+	want = yaml.MapSlice{{Key: "new", Value: 2}}
+	if got := renameKey(doc, "old", "new"); !reflect.DeepEqual(got, want) {
+		t.Errorf("want the existing value kept, got %v", got)
+	}
+}
+
+func Test_setDefaultKey(t *testing.T) {
+	doc := yaml.MapSlice{{Key: "a", Value: true}}
+
+	doc = setDefaultKey(doc, "a", false)
+	doc = setDefaultKey(doc, "b", false)
+	want := yaml.MapSlice{{Key: "a", Value: true}, {Key: "b", Value: false}}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("want %v, got %v", want, doc)
+	}
+}
+
+func Test_hasSecret(t *testing.T) {
+	doc := v1Doc()
+	if !hasSecret(doc, "s3-secret-key") {
+		t.Error("want s3-secret-key found")
+	}
+	if hasSecret(doc, "payload-secret") || hasSecret(yaml.MapSlice{}, "payload-secret") {
+		t.Error("want payload-secret not found")
+	}
+}
+
+func Test_addLiteralSecret(t *testing.T) {
+	doc, err := addLiteralSecret(v1Doc(), "payload-secret", "value", "default", "openfaas-fn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets, _ := getKey(doc, "secrets")
+	list, _ := secrets.([]interface{})
+	want := yaml.MapSlice{
+		{Key: "name", Value: "payload-secret"},
+		{Key: "literals", Value: []interface{}{
+			yaml.MapSlice{{Key: "name", Value: "payload-secret"}, {Key: "value", Value: "value"}},
+		}},
+		{Key: "filters", Value: []interface{}{"default"}},
+		{Key: "namespace", Value: "openfaas-fn"},
+	}
+	if len(list) != 2 || !reflect.DeepEqual(list[1], want) {
+		t.Errorf("want %v appended, got %v", want, list)
+	}
+}
+
+func Test_addLiteralSecret_Encrypted(t *testing.T) {
+	os.Setenv(passphraseEnv, "passphrase")
+	defer func() {
+		os.Unsetenv(passphraseEnv)
+		passphrase = ""
+		derivedKeys = map[string][]byte{}
+	}()
+
+	encrypted, err := encryptValue("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := yaml.MapSlice{{Key: "secrets", Value: []interface{}{
+		yaml.MapSlice{
+			{Key: "name", Value: "s3-secret-key"},
+			{Key: "literals", Value: []interface{}{
+				yaml.MapSlice{{Key: "name", Value: "s3-secret-key"}, {Key: "value", Value: encrypted}},
+			}},
+		},
+	}}}
+
+	if doc, err = addLiteralSecret(doc, "payload-secret", "value", "default", "openfaas-fn"); err != nil {
+		t.Fatal(err)
+	}
+
+	secrets, _ := getKey(doc, "secrets")
+	added := secrets.([]interface{})[1].(yaml.MapSlice)
+	literals, _ := getKey(added, "literals")
+	value, _ := getKey(literals.([]interface{})[0].(yaml.MapSlice), "value")
+
+	if !isEncrypted(value.(string)) {
+		t.Fatalf("want the value encrypted, got %v", value)
+	}
+	if plain, err := decryptValue(value.(string)); err != nil || plain != "value" {
+		t.Errorf("want value, got %q %v", plain, err)
+	}
+}
+
+func Test_docSchema(t *testing.T) {
+	cases := []struct {
+		name string
+		doc  yaml.MapSlice
+	}{
+		{"marker", yaml.MapSlice{{Key: schemaMarkerField, Value: schemas[0].Version}}},
+		{"version", yaml.MapSlice{{Key: "openfaas_cloud_version", Value: defaultVersion}}},
+		{"unknown version", yaml.MapSlice{{Key: "openfaas_cloud_version", Value: "latest"}}},
+		{"empty", yaml.MapSlice{}},
+	}
+
+	for _, c := range cases {
+		if s := docSchema(c.doc); s.Version != schemas[0].Version {
+			t.Errorf("%s: want schema %s, got %s", c.name, schemas[0].Version, s.Version)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package actions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+// kubernetesOnlyFields lists the init.yml fields which only apply when orchestrating with
+// Kubernetes. TLS is issued by cert-manager, which is not available on Docker Swarm
+var kubernetesOnlyFields = []string{"network_policies", "ingress", "tls", "tls_config"}
+
+// kubernetesOnlyFilters lists the secret filters for the cert-manager DNS providers
+var kubernetesOnlyFilters = []string{digOceanDNS.Filter[0], gCloudDNS.Filter[0], awsDNS.Filter[0]}
+
+// appliesTo reports whether the init.yml field with the given path is used by the orchestrator
+func appliesTo(orchestration string, path string) bool {
+	if orchestration != swarm {
+		return true
+	}
+
+	top := strings.SplitN(path, ".", 2)[0]
+	for _, f := range kubernetesOnlyFields {
+		if f == top {
+			return false
+		}
+	}
+	return true
+}
+
+// checkOrchestrator returns an error for each setting or secret of the init.yml which is
+// not used by its orchestrator
+func checkOrchestrator(yml types.InitYaml) []error {
+	errs := []error{}
+	if yml.Orchestration != swarm {
+		return errs
+	}
+
+	if yml.NetworkPolicies {
+		errs = append(errs, fmt.Errorf("network_policies are only supported on Kubernetes"))
+	}
+	if yml.Ingress != "" {
+		errs = append(errs, fmt.Errorf("ingress %s is only supported on Kubernetes, use a load balancer in front of the swarm instead", yml.Ingress))
+	}
+	if yml.TLS || yml.TLSConfig != (types.TLSConfig{}) {
+		errs = append(errs, fmt.Errorf("tls is issued by cert-manager, which is only supported on Kubernetes"))
+	}
+
+	for _, s := range yml.Secrets {
+		if s.Namespace != "" {
+			errs = append(errs, fmt.Errorf("secret %s has a namespace, which Docker Swarm does not support", s.Name))
+		}
+		if hasAnyFilter(s, kubernetesOnlyFilters) {
+			errs = append(errs, fmt.Errorf("secret %s is for a cert-manager DNS provider, which is only supported on Kubernetes", s.Name))
+		}
+	}
+	return errs
+}
+
+// orchestratorSecret returns the secret without its namespace on Docker Swarm, which does not
+// support namespaces
+func orchestratorSecret(orchestration string, secret types.Secret) types.Secret {
+	if orchestration == swarm {
+		secret.Namespace = ""
+	}
+	return secret
+}
@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func Test_appliesTo(t *testing.T) {
+	for _, orchestration := range []string{kubernetes, swarm} {
+		for _, path := range append(kubernetesOnlyFields, "tls_config.email") {
+			if got := appliesTo(orchestration, path); got != (orchestration == kubernetes) {
+				t.Errorf("appliesTo(%s, %s): want %t, got %t", orchestration, path, orchestration == kubernetes, got)
+			}
+		}
+
+		for _, path := range []string{"root_domain", "secrets", "scale_to_zero"} {
+			if !appliesTo(orchestration, path) {
+				t.Errorf("appliesTo(%s, %s): want true", orchestration, path)
+			}
+		}
+	}
+}
+
+func Test_checkOrchestrator(t *testing.T) {
+	cases := []struct {
+		name string
+		yml  types.InitYaml
+		want int
+	}{
+		{"network policies", types.InitYaml{NetworkPolicies: true}, 1},
+		{"ingress", types.InitYaml{Ingress: "host"}, 1},
+		{"tls", types.InitYaml{TLS: true}, 1},
+		{"tls config", types.InitYaml{TLSConfig: types.TLSConfig{Email: "me@example.com"}}, 1},
+		{"namespace", types.InitYaml{Secrets: []types.Secret{{Name: "s3-secret-key", Namespace: "openfaas-fn"}}}, 1},
+		{"dns secret", types.InitYaml{Secrets: []types.Secret{{Name: "digitalocean-dns", Filters: digOceanDNS.Filter}}}, 1},
+		{"shared settings", types.InitYaml{RootDomain: "example.com", ScaleToZero: true, Secrets: []types.Secret{{Name: "payload-secret"}}}, 0},
+	}
+
+	for _, c := range cases {
+		c.yml.Orchestration = kubernetes
+		if errs := checkOrchestrator(c.yml); len(errs) != 0 {
+			t.Errorf("%s on kubernetes: want no errors, got %v", c.name, errs)
+		}
+
+		c.yml.Orchestration = swarm
+		if errs := checkOrchestrator(c.yml); len(errs) != c.want {
+			t.Errorf("%s on swarm: want %d errors, got %v", c.name, c.want, errs)
+		}
+	}
+}
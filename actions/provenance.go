@@ -0,0 +1,269 @@
+package actions
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/burtonr/ofc-wizard/types"
+	"gopkg.in/yaml.v2"
+)
+
+// Where the value of a field came from
+var (
+	sourcePrompt   = "prompt"
+	sourceFlag     = "flag --set"
+	sourceEnv      = "environment"
+	sourceLocked   = "config file (locked)"
+	sourceConfig   = "config file default"
+	sourceExisting = "existing init.yml"
+	sourceDefault  = "built-in default"
+	sourceUnknown  = "unknown (edited outside the wizard)"
+	sourceDocker   = "docker login"
+)
+
+// provenanceFile records where each value of the generated init.yml came from
+var provenanceFile = ".ofc-wizard-provenance.yml"
+
+// provenance holds the source of each init.yml path answered during GenerateYaml
+var provenance = map[string]string{}
+
+// answerPaths maps each answer key to the path of the init.yml field it sets
+var answerPaths = map[string]string{
+	orchestrationKey:        "orchestration",
+	rootDomainKey:           "root_domain",
+	registryKey:             "registry",
+	ecrRegionKey:            "ecr_config.ecr_region",
+	scmKey:                  "scm",
+	enableOAuthKey:          "enable_oauth",
+	githubAppIDKey:          "github.app_id",
+	gitlabInstanceKey:       "gitlab.gitlab_instance",
+	oauthClientIDKey:        "oauth.client_id",
+	oauthProviderBaseURLKey: "oauth.oauth_provider_base_url",
+	s3URLKey:                "s3.s3_url",
+	s3RegionKey:             "s3.s3_region",
+	s3BucketKey:             "s3.s3_bucket",
+	s3TLSKey:                "s3.s3_tls",
+	dnsProviderKey:          "tls_config.dns_service",
+	tlsKey:                  "tls",
+	tlsEmailKey:             "tls_config.email",
+	tlsIssuerTypeKey:        "tls_config.issuer_type",
+	gcpProjectIDKey:         "tls_config.project_id",
+	awsRegionKey:            "tls_config.region",
+	awsAccessKeyIDKey:       "tls_config.access_key_id",
+	auditToKey:              "slack.url",
+	auditURLKey:             "slack.url",
+	customersURLKey:         "customers_url",
+	customersSourceKey:      "customers_secret",
+	enableDockerfileKey:     "enable_dockerfile_lang",
+	scaleToZeroKey:          "scale_to_zero",
+	ofcVersionKey:           "openfaas_cloud_version",
+	networkPoliciesKey:      "network_policies",
+	ingressKey:              "ingress",
+	buildBranchKey:          "build_branch",
+	customTemplatesKey:      "deployment.custom_templates",
+}
+
+// fieldValue is a single leaf value of an init.yml, identified by its path
+type fieldValue struct {
+	Path  string
+	Value string
+}
+
+// recordSource records the source of the answer for the given key
+func recordSource(key string, source string) {
+	if path, ok := answerPaths[key]; ok {
+		provenance[path] = source
+	}
+}
+
+// recordSecretSource records the source of the secret written by the wizard, unless it is
+// unchanged from the existing init.yml
+func recordSecretSource(secret types.Secret, source string) {
+	if existingYaml != nil {
+		if i := findSecret(existingYaml.Secrets, secret.Name); i >= 0 && reflect.DeepEqual(existingYaml.Secrets[i], secret) {
+			return
+		}
+	}
+	provenance[fmt.Sprintf("secrets[%s]", secret.Name)] = source
+}
+
+// flattenYaml returns every leaf value of the init.yml in document order. Items of lists
+// with a name are identified by that name, eg: secrets[payload-secret].literals[payload-secret].value
+func flattenYaml(yml types.InitYaml) ([]fieldValue, error) {
+	out, err := yaml.Marshal(yml)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := yaml.MapSlice{}
+	if err := yaml.Unmarshal(out, &doc); err != nil {
+		return nil, err
+	}
+
+	return flattenDoc(doc), nil
+}
+
+// flattenDoc returns every leaf value of the yaml document in document order
+func flattenDoc(doc yaml.MapSlice) []fieldValue {
+	fields := []fieldValue{}
+	flattenValue("", doc, &fields)
+	return fields
+}
+
+func flattenValue(path string, value interface{}, fields *[]fieldValue) {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		for _, item := range v {
+			flattenValue(joinPath(path, fmt.Sprint(item.Key)), item.Value, fields)
+		}
+	case []interface{}:
+		for i, item := range v {
+			flattenValue(fmt.Sprintf("%s[%s]", path, itemID(item, i)), item, fields)
+		}
+	case nil:
+		*fields = append(*fields, fieldValue{Path: path})
+	default:
+		*fields = append(*fields, fieldValue{Path: path, Value: fmt.Sprint(v)})
+	}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// itemID returns the name of a list item, or its index when it has no name
+func itemID(item interface{}, index int) string {
+	if m, ok := item.(yaml.MapSlice); ok {
+		for _, kv := range m {
+			if kv.Key == "name" {
+				return fmt.Sprint(kv.Value)
+			}
+		}
+	}
+	return fmt.Sprint(index)
+}
+
+// secretPaths are the fields outside of the secrets which hold credentials, such as the
+// Slack webhook URL of the audit trail
+var secretPaths = []string{"slack.url", "tls_config.access_key_id"}
+
+// isSecretPath reports whether the path holds a secret value which must not be printed. A
+// value_command is included as it may hold a token
+func isSecretPath(path string) bool {
+	if strings.HasPrefix(path, "secrets[") {
+		return strings.HasSuffix(path, ".value") || strings.HasSuffix(path, ".value_command")
+	}
+	return contains(secretPaths, path)
+}
+
+// redact hides the value of secret paths
+func redact(path string, value string) string {
+	if isSecretPath(path) && value != "" {
+		return "********"
+	}
+	return value
+}
+
+// sourceOf returns the recorded source of the path, or of the closest parent path
+func sourceOf(sources map[string]string, path string) (string, bool) {
+	for p := path; p != ""; {
+		if source, ok := sources[p]; ok {
+			return source, true
+		}
+
+		i := strings.LastIndexAny(p, ".[")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+	return "", false
+}
+
+// recordUnansweredSources records the source of each field of the generated file that was
+// not answered, by comparing it with the existing init.yml
+func recordUnansweredSources(yml types.InitYaml) {
+	existing := map[string]string{}
+	if existingYaml != nil {
+		if fields, err := flattenYaml(*existingYaml); err == nil {
+			for _, f := range fields {
+				existing[f.Path] = f.Value
+			}
+		}
+	}
+
+	fields, err := flattenYaml(yml)
+	if err != nil {
+		return
+	}
+
+	for _, f := range fields {
+		if _, ok := sourceOf(provenance, f.Path); ok {
+			continue
+		}
+
+		if v, ok := existing[f.Path]; ok && v == f.Value && f.Value != "" {
+			provenance[f.Path] = sourceExisting
+		} else {
+			provenance[f.Path] = sourceDefault
+		}
+	}
+}
+
+// writeProvenance saves the recorded sources alongside the init.yml
+func writeProvenance() {
+	out, err := yaml.Marshal(provenance)
+	if err != nil {
+		fmt.Printf("Trouble recording where values came from: %s\n", err.Error())
+		return
+	}
+
+	if err := ioutil.WriteFile(provenanceFile, out, 0644); err != nil {
+		fmt.Printf("Trouble recording where values came from: %s\n", err.Error())
+	}
+}
+
+// loadProvenance reads the sources recorded by the last run of the wizard
+func loadProvenance() map[string]string {
+	sources := map[string]string{}
+
+	yamlBytes, err := ioutil.ReadFile(provenanceFile)
+	if err != nil {
+		return sources
+	}
+
+	if err := yaml.Unmarshal(yamlBytes, &sources); err != nil {
+		fmt.Fprintf(os.Stderr, "-%s gave error: %s\n", provenanceFile, err.Error())
+	}
+	return sources
+}
+
+// ExplainValues prints each value of the init.yml along with where it came from
+func ExplainValues() {
+	yml := LoadInitFile()
+	sources := loadProvenance()
+
+	fields, err := flattenYaml(*yml)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "-yaml file gave error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tVALUE\tSOURCE")
+	for _, f := range fields {
+		source, ok := sourceOf(sources, f.Path)
+		if !ok {
+			source = sourceUnknown
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", f.Path, redact(f.Path, f.Value), source)
+	}
+	w.Flush()
+}
@@ -0,0 +1,125 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func Test_flattenYaml_NamedItems(t *testing.T) {
+	yml := types.InitYaml{
+		RootDomain: "example.com",
+		Secrets: []types.Secret{
+			{Name: "payload-secret", Literals: []types.Literal{{Name: "payload-secret", Value: "abc"}}},
+		},
+	}
+
+	fields, err := flattenYaml(yml)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := map[string]string{}
+	for _, f := range fields {
+		values[f.Path] = f.Value
+	}
+
+	if values["root_domain"] != "example.com" {
+		t.Errorf("want root_domain, got %v", values)
+	}
+	if values["secrets[payload-secret].literals[payload-secret].value"] != "abc" {
+		t.Errorf("want the literal identified by name, got %v", values)
+	}
+}
+
+func Test_redact(t *testing.T) {
+	cases := []struct {
+		path  string
+		value string
+		want  string
+	}{
+		{"secrets[payload-secret].literals[payload-secret].value", "abc", "********"},
+		{"secrets[payload-secret].literals[payload-secret].value", "", ""},
+		{"secrets[payload-secret].literals[payload-secret].name", "payload-secret", "payload-secret"},
+		{"secrets[dns].files[token].value_command", "vault read -field=token dns", "********"},
+		{"secrets[dns].files[token].value_from", "~/token", "~/token"},
+		{"slack.url", "https://hooks.slack.com/services/T000/B000/XXXX", "********"},
+		{"tls_config.access_key_id", "AKIAEXAMPLE", "********"},
+		{"root_domain", "example.com", "example.com"},
+	}
+
+	for _, c := range cases {
+		if got := redact(c.path, c.value); got != c.want {
+			t.Errorf("%s: want %q, got %q", c.path, c.want, got)
+		}
+	}
+}
+
+func Test_sourceOf(t *testing.T) {
+	sources := map[string]string{
+		"root_domain":              sourcePrompt,
+		"secrets[registry-secret]": sourceExisting,
+	}
+
+	if source, ok := sourceOf(sources, "root_domain"); !ok || source != sourcePrompt {
+		t.Errorf("want %s, got %s", sourcePrompt, source)
+	}
+	if source, ok := sourceOf(sources, "secrets[registry-secret].files[config.json].value_from"); !ok || source != sourceExisting {
+		t.Errorf("want the source of the secret, got %s", source)
+	}
+	if _, ok := sourceOf(sources, "tls"); ok {
+		t.Error("want no source for tls")
+	}
+}
+
+func Test_recordSource(t *testing.T) {
+	provenance = map[string]string{}
+	defer func() { provenance = map[string]string{} }()
+
+	recordSource(rootDomainKey, sourceFlag)
+	recordSource("not_an_answer", sourceFlag)
+
+	if provenance["root_domain"] != sourceFlag || len(provenance) != 1 {
+		t.Errorf("want only root_domain recorded, got %v", provenance)
+	}
+}
+
+func Test_addSecret_RecordsSource(t *testing.T) {
+	provenance = map[string]string{}
+	defer func() { provenance = map[string]string{} }()
+
+	yml := &types.InitYaml{Orchestration: swarm}
+	secret := types.Secret{
+		Name:      registrySecretName,
+		Files:     []types.FileValue{{Name: "config.json", ValueFrom: registryConfigFile}},
+		Namespace: functionsNamespace,
+	}
+	addSecret(yml, secret, sourceDocker)
+
+	if yml.Secrets[0].Namespace != "" {
+		t.Errorf("want no namespace on swarm, got %s", yml.Secrets[0].Namespace)
+	}
+	if errs := checkOrchestrator(*yml); len(errs) > 0 {
+		t.Errorf("want no swarm errors, got %v", errs)
+	}
+
+	source, ok := sourceOf(provenance, "secrets[registry-secret].files[config.json].value_from")
+	if !ok || source != sourceDocker {
+		t.Errorf("want %s, got %s", sourceDocker, source)
+	}
+}
+
+func Test_recordSecretSource_Unchanged(t *testing.T) {
+	provenance = map[string]string{}
+	secret := types.Secret{Name: "payload-secret", Literals: []types.Literal{{Name: "payload-secret", Value: "a"}}}
+	existingYaml = &types.InitYaml{Secrets: []types.Secret{secret}}
+	defer func() {
+		provenance = map[string]string{}
+		existingYaml = nil
+	}()
+
+	recordSecretSource(secret, sourcePrompt)
+	if _, ok := provenance["secrets[payload-secret]"]; ok {
+		t.Error("want no source for a secret kept from the existing init.yml")
+	}
+}
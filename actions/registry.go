@@ -0,0 +1,258 @@
+package actions
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/burtonr/ofc-wizard/types"
+	homedir "github.com/mitchellh/go-homedir"
+	"gopkg.in/AlecAivazis/survey.v1"
+)
+
+var (
+	dockerConfigFile   = "~/.docker/config.json"
+	registryConfigFile = "credentials/config.json"
+	registrySecretName = "registry-secret"
+	dockerHub          = "docker.io"
+	dockerHubAuthKey   = "https://index.docker.io/v1/"
+)
+
+// Where the registry credentials come from
+var (
+	registryFromDockerConfig = "docker-config"
+	registryFromLogin        = "login"
+	registryFromSkip         = "skip"
+)
+
+// dockerHubHosts are the names Docker Hub is known by in a docker config.json
+var dockerHubHosts = []string{dockerHub, "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com"}
+
+// askRegistryCredentials asks for the credentials to push images to the registry, writes a
+// config.json holding only those credentials and returns the registry-secret which reads it,
+// along with where the credentials came from. Nil is returned when the credentials are
+// skipped or the existing secret is kept
+func askRegistryCredentials(ref registryRef, secrets []types.Secret) (*types.Secret, string, error) {
+	if ref.Type == ecrRegistry {
+		fmt.Printf("%s logins expire after 12 hours, so the %s is used instead of a %s\n", ecrRegistry.Name, ecrSecretName, registrySecretName)
+		return nil, "", nil
+	}
+
+	if findSecret(secrets, registrySecretName) >= 0 && !isPreset(registryCredentialsKey) {
+		replace := false
+		replaceQuestion := &survey.Confirm{Message: fmt.Sprintf("Replace the existing %s?", registrySecretName)}
+		if err := survey.AskOne(replaceQuestion, &replace, nil); err != nil || !replace {
+			return nil, "", err
+		}
+	}
+
+	host := ref.Host
+	matches, err := dockerConfigAuths(host)
+	if err != nil {
+		fmt.Printf("Unable to read %s: %s\n", dockerConfigFile, err.Error())
+	}
+
+	options := []string{registryFromLogin, registryFromSkip}
+	if len(matches) > 0 {
+		options = append([]string{registryFromDockerConfig}, options...)
+	}
+
+	source := ""
+	sourceQuestion := &survey.Select{
+		Message: fmt.Sprintf("Choose where the credentials for %s come from:", host),
+		Options: options,
+		Help:    fmt.Sprintf("%s uses the login saved by 'docker login', %s asks for a username and password, %s leaves the %s to be added later", registryFromDockerConfig, registryFromLogin, registryFromSkip, registrySecretName),
+	}
+	if err := askOne(registryCredentialsKey, sourceQuestion, &source, nil); err != nil {
+		return nil, "", err
+	}
+
+	auth, from := "", sourcePrompt
+	switch source {
+	case registryFromDockerConfig:
+		if auth, err = chooseDockerConfigAuth(matches); err != nil {
+			return nil, "", err
+		}
+		from = fmt.Sprintf("%s (%s)", sourceDocker, dockerConfigFile)
+	case registryFromLogin:
+		if auth, err = askRegistryLogin(ref); err != nil {
+			return nil, "", err
+		}
+	default:
+		return nil, "", nil
+	}
+
+	if err := writeRegistryConfig(host, auth); err != nil {
+		return nil, "", err
+	}
+	fmt.Printf("Wrote the credentials for %s to %s\n", host, registryConfigFile)
+
+	return &types.Secret{
+		Name:      registrySecretName,
+		Files:     []types.FileValue{{Name: "config.json", ValueFrom: registryConfigFile}},
+		Filters:   []string{defaultFilter},
+		Namespace: functionsNamespace,
+	}, from, nil
+}
+
+// normaliseRegistryHost returns the host of a docker config.json auths key, which may be a URL
+func normaliseRegistryHost(key string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	host = strings.SplitN(host, "/", 2)[0]
+
+	for _, h := range dockerHubHosts {
+		if host == h {
+			return dockerHub
+		}
+	}
+	return host
+}
+
+// dockerConfigAuths returns the usable credentials from the docker config.json for the host,
+// keyed by their auths key. Credentials kept in a credential store can not be read
+func dockerConfigAuths(host string) (map[string]string, error) {
+	path, err := homedir.Expand(dockerConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	config := types.DockerConfig{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	matches := map[string]string{}
+	for key, a := range config.Auths {
+		if normaliseRegistryHost(key) != host {
+			continue
+		}
+
+		if a.Auth == "" {
+			fmt.Printf("The credentials for %s are kept by a credential store and can not be used, enter them instead\n", key)
+			continue
+		}
+		matches[key] = a.Auth
+	}
+	return matches, nil
+}
+
+// chooseDockerConfigAuth returns the credentials to use, asking which when there are several
+func chooseDockerConfigAuth(matches map[string]string) (string, error) {
+	keys := []string{}
+	for k := range matches {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	key := keys[0]
+	if len(keys) > 1 {
+		keyQuestion := &survey.Select{Message: "Choose the saved login to use:", Options: keys}
+		if err := survey.AskOne(keyQuestion, &key, nil); err != nil {
+			return "", err
+		}
+	}
+
+	if user := authUser(matches[key]); user != "" {
+		fmt.Printf("Using the login of %s for %s\n", user, key)
+	}
+	return matches[key], nil
+}
+
+// authUser returns the username of the base64 encoded username:password
+func authUser(auth string) string {
+	decoded, err := base64.StdEncoding.DecodeString(auth)
+	if err != nil {
+		return ""
+	}
+	return strings.SplitN(string(decoded), ":", 2)[0]
+}
+
+// askRegistryLogin asks for the username and password of the registry, returning them encoded
+// as a docker config.json auth. Google Container Registry is given the path of a JSON key instead
+func askRegistryLogin(ref registryRef) (string, error) {
+	passwordPrompt := survey.Prompt(&survey.Password{
+		Message: fmt.Sprintf("Enter the password or access token for %s:", ref.Host),
+		Help:    ref.Type.LoginHelp,
+	})
+	if ref.Type == gcrRegistry {
+		passwordPrompt = &survey.Input{
+			Message: "Enter the path of the service account JSON key:",
+			Help:    "The service account needs the Storage Admin role for the bucket of the registry",
+		}
+	}
+
+	var questions = []*survey.Question{
+		{
+			Name: "Username",
+			Prompt: &survey.Input{
+				Message: fmt.Sprintf("Enter the username for %s:", ref.Host),
+				Default: ref.Type.Username,
+				Help:    ref.Type.LoginHelp,
+			},
+			Validate: survey.Required,
+		},
+		{
+			Name:     "Password",
+			Prompt:   passwordPrompt,
+			Validate: survey.Required,
+		},
+	}
+
+	keys := map[string]string{
+		"Username": registryUsernameKey,
+		"Password": registryPasswordKey,
+	}
+
+	login := struct {
+		Username string
+		Password string
+	}{}
+	if err := ask(questions, keys, &login); err != nil {
+		return "", err
+	}
+
+	if ref.Type == gcrRegistry {
+		path, err := homedir.Expand(login.Password)
+		if err != nil {
+			return "", err
+		}
+		key, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		login.Password = strings.TrimSpace(string(key))
+	}
+	return base64.StdEncoding.EncodeToString([]byte(login.Username + ":" + login.Password)), nil
+}
+
+// writeRegistryConfig writes a docker config.json holding only the credentials for the host,
+// readable by the current user only
+func writeRegistryConfig(host string, auth string) error {
+	key := host
+	if host == dockerHub {
+		key = dockerHubAuthKey
+	}
+
+	config := types.DockerConfig{Auths: map[string]types.DockerAuth{key: {Auth: auth}}}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(registryConfigFile), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(registryConfigFile, data, 0600)
+}
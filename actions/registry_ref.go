@@ -0,0 +1,145 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// registryType is a well-known registry, detected from the host of the registry
+type registryType struct {
+	Name string
+	// NeedsNamespace is set when images can only be pushed below a user or organisation
+	NeedsNamespace bool
+	// LoginHelp explains which username and password to enter for the registry
+	LoginHelp string
+	// Username is offered as the username, when the registry expects a fixed one
+	Username string
+}
+
+var (
+	dockerHubRegistry = registryType{
+		Name:           "Docker Hub",
+		NeedsNamespace: true,
+		LoginHelp:      "Use your Docker Hub username and an access token from Account Settings > Security",
+	}
+	ghcrRegistry = registryType{
+		Name:           "GitHub Container Registry",
+		NeedsNamespace: true,
+		LoginHelp:      "Use your GitHub username and a personal access token with the write:packages scope",
+	}
+	ecrRegistry = registryType{
+		Name: "Amazon ECR",
+	}
+	gcrRegistry = registryType{
+		Name:           "Google Container Registry",
+		NeedsNamespace: true,
+		LoginHelp:      "Use _json_key as the username, and the service account JSON key as the password",
+		Username:       "_json_key",
+	}
+	quayRegistry = registryType{
+		Name:           "Quay",
+		NeedsNamespace: true,
+		LoginHelp:      "Use the name and token of a robot account with write access to the repositories",
+	}
+	otherRegistry = registryType{
+		Name:      "registry",
+		LoginHelp: "Use a login with permission to push images to the registry",
+	}
+)
+
+var (
+	registryHostPattern      = regexp.MustCompile(`^(localhost|[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*)(:[0-9]+)?$`)
+	registryComponentPattern = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*$`)
+	ecrHostPattern           = regexp.MustCompile(`^([0-9]{12})\.dkr\.ecr\.([a-z0-9-]+)\.amazonaws\.com(\.cn)?$`)
+)
+
+// registryRef is a parsed registry reference, host[:port]/namespace/
+type registryRef struct {
+	Host      string
+	Namespace string
+	Type      registryType
+	// Account and Region are set for Amazon ECR registries
+	Account string
+	Region  string
+}
+
+// String returns the registry in the form written to the init.yml, with a trailing slash
+func (r registryRef) String() string {
+	if r.Namespace == "" {
+		return r.Host + "/"
+	}
+	return r.Host + "/" + r.Namespace + "/"
+}
+
+// parseRegistry parses the registry images are pushed to. Docker Hub may be given without a
+// host, eg: your-name/, and a missing trailing slash is added
+func parseRegistry(value string) (registryRef, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return registryRef{}, errors.New("The registry is required")
+	}
+	if strings.Contains(value, "://") {
+		return registryRef{}, errors.New("The registry must not include a scheme such as https://")
+	}
+
+	parts := strings.Split(strings.TrimSuffix(value, "/"), "/")
+	host := parts[0]
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		host = dockerHub
+	} else {
+		parts = parts[1:]
+	}
+	host = normaliseRegistryHost(strings.ToLower(host))
+
+	if !registryHostPattern.MatchString(host) {
+		return registryRef{}, fmt.Errorf("The registry host %q is not a valid host name", host)
+	}
+
+	for _, p := range parts {
+		if strings.ContainsAny(p, ":@") {
+			return registryRef{}, errors.New("The registry must not include a tag or digest, images are tagged by OpenFaaS Cloud")
+		}
+		if !registryComponentPattern.MatchString(p) {
+			return registryRef{}, fmt.Errorf("%q is not a valid registry namespace, use lower case letters, digits and separators", p)
+		}
+	}
+
+	ref := registryRef{Host: host, Namespace: strings.Join(parts, "/"), Type: detectRegistryType(host)}
+	if m := ecrHostPattern.FindStringSubmatch(host); m != nil {
+		ref.Account, ref.Region = m[1], m[2]
+	}
+
+	if ref.Type.NeedsNamespace && ref.Namespace == "" {
+		return registryRef{}, fmt.Errorf("%s needs a user or organisation, eg: %s/your-name/", ref.Type.Name, host)
+	}
+	return ref, nil
+}
+
+// detectRegistryType returns the well-known registry of the host
+func detectRegistryType(host string) registryType {
+	switch {
+	case host == dockerHub:
+		return dockerHubRegistry
+	case host == "ghcr.io":
+		return ghcrRegistry
+	case ecrHostPattern.MatchString(host):
+		return ecrRegistry
+	case host == "gcr.io" || strings.HasSuffix(host, ".gcr.io"):
+		return gcrRegistry
+	case host == "quay.io":
+		return quayRegistry
+	}
+	return otherRegistry
+}
+
+// validateRegistry is the survey validator for the registry question
+func validateRegistry(val interface{}) error {
+	str, ok := val.(string)
+	if !ok {
+		return errors.New("The registry must be a string")
+	}
+	_, err := parseRegistry(str)
+	return err
+}
@@ -0,0 +1,72 @@
+package actions
+
+import (
+	"testing"
+)
+
+func Test_parseRegistry(t *testing.T) {
+	cases := []struct {
+		value     string
+		host      string
+		namespace string
+		name      string
+		region    string
+	}{
+		{"your-name", dockerHub, "your-name", dockerHubRegistry.Name, ""},
+		{"your-name/", dockerHub, "your-name", dockerHubRegistry.Name, ""},
+		{"index.docker.io/your-name/", dockerHub, "your-name", dockerHubRegistry.Name, ""},
+		{"GHCR.io/openfaas/functions", "ghcr.io", "openfaas/functions", ghcrRegistry.Name, ""},
+		{"123456789012.dkr.ecr.eu-west-1.amazonaws.com/", "123456789012.dkr.ecr.eu-west-1.amazonaws.com", "", ecrRegistry.Name, "eu-west-1"},
+		{"eu.gcr.io/project/", "eu.gcr.io", "project", gcrRegistry.Name, ""},
+		{"localhost:5000", "localhost:5000", "", otherRegistry.Name, ""},
+		{" registry.example.com:5000/team/ ", "registry.example.com:5000", "team", otherRegistry.Name, ""},
+	}
+
+	for _, c := range cases {
+		ref, err := parseRegistry(c.value)
+		if err != nil {
+			t.Errorf("%q: want no error, got %s", c.value, err)
+			continue
+		}
+		if ref.Host != c.host || ref.Namespace != c.namespace || ref.Type.Name != c.name || ref.Region != c.region {
+			t.Errorf("%q: want %s %s %s %s, got %s %s %s %s", c.value, c.host, c.namespace, c.name, c.region, ref.Host, ref.Namespace, ref.Type.Name, ref.Region)
+		}
+	}
+}
+
+func Test_parseRegistry_Invalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"https://registry.example.com/",
+		"docker.io/",
+		"ghcr.io",
+		"your-name/image:latest",
+		"registry.example.com/Team/",
+		"registry_example.com/team/",
+	} {
+		if _, err := parseRegistry(value); err == nil {
+			t.Errorf("%q: want an error", value)
+		}
+	}
+}
+
+func Test_registryRef_String(t *testing.T) {
+	ref, _ := parseRegistry("your-name")
+	if ref.String() != "docker.io/your-name/" {
+		t.Errorf("want docker.io/your-name/, got %s", ref.String())
+	}
+
+	ref, _ = parseRegistry("localhost:5000")
+	if ref.String() != "localhost:5000/" {
+		t.Errorf("want localhost:5000/, got %s", ref.String())
+	}
+}
+
+func Test_validateRegistry(t *testing.T) {
+	if err := validateRegistry(1); err == nil {
+		t.Error("want an error for a value which is not a string")
+	}
+	if err := validateRegistry("ghcr.io/openfaas/"); err != nil {
+		t.Errorf("want no error, got %s", err)
+	}
+}
@@ -0,0 +1,211 @@
+package actions
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/burtonr/ofc-wizard/types"
+	homedir "github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v2"
+)
+
+// rotationLogFile keeps the previous values of rotated secrets, so they can be restored
+var rotationLogFile = ".ofc-wizard-rotations.yml"
+
+// rotation describes how to rotate a secret whose value is generated
+type rotation struct {
+	// Keys lists the keys to regenerate, all of the keys are regenerated when empty
+	Keys     []string
+	FollowUp []string
+}
+
+// rotations lists the secrets with generated values, and the steps needed after rotating them
+var rotations = map[string]rotation{
+	"payload-secret": {
+		FollowUp: []string{
+			"Run ofc-bootstrap again, or update the payload-secret in the openfaas and openfaas-fn namespaces",
+			"Restart the OpenFaaS Cloud functions so they use the new value",
+		},
+	},
+	"github-webhook-secret": {
+		FollowUp: []string{
+			"Run ofc-bootstrap again, or update the github-webhook-secret in the openfaas-fn namespace",
+			"Update the webhook secret of your GitHub App in Settings > Developer settings > GitHub Apps",
+		},
+	},
+	"gitlab-webhook-secret": {
+		FollowUp: []string{
+			"Run ofc-bootstrap again, or update the gitlab-webhook-secret in the openfaas-fn namespace",
+			"Update the secret token of the system hook in GitLab under Admin Area > System Hooks",
+		},
+	},
+	"basic-auth": {
+		Keys: []string{"basic-auth-password"},
+		FollowUp: []string{
+			"Run ofc-bootstrap again, or update the basic-auth secret in the openfaas namespace",
+			"Restart the gateway, then log in again with faas-cli login",
+		},
+	},
+}
+
+// rotationEntry is a previous value of a rotated secret key
+type rotationEntry struct {
+	Time          string `yaml:"time"`
+	Secret        string `yaml:"secret"`
+	Key           string `yaml:"key"`
+	PreviousValue string `yaml:"previous_value"`
+}
+
+// RotateSecret generates new values for the secret in the init.yml at the path, or in the
+// files its keys are read from, logging the previous values and printing the follow up steps
+func RotateSecret(path string, name string) {
+	r, ok := rotations[name]
+	if !ok {
+		exitWithError(fmt.Errorf("secret %s does not have a generated value, use 'ofc-wizard secrets set' to change it", name))
+	}
+
+	doc, yml := loadInitDoc(path)
+	i := findSecret(yml.Secrets, name)
+	if i < 0 {
+		exitWithError(fmt.Errorf("secret %s does not exist", name))
+	}
+
+	secret := &yml.Secrets[i]
+	now := time.Now().UTC().Format(time.RFC3339)
+	entries := []rotationEntry{}
+	keys := []secretKey{}
+	values := []string{}
+
+	for _, key := range secretKeys(*secret) {
+		if len(r.Keys) > 0 && !contains(r.Keys, key.Name) {
+			continue
+		}
+
+		previous, err := keyValue(key)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		value, err := generateSecretValue()
+		if err != nil {
+			exitWithError(err)
+		}
+
+		// the log is kept next to init.yml, so it is encrypted along with it
+		if encryptLiterals && previous != "" {
+			if previous, err = encryptValue(previous); err != nil {
+				exitWithError(err)
+			}
+		}
+
+		keys = append(keys, key)
+		values = append(values, value)
+		entries = append(entries, rotationEntry{Time: now, Secret: name, Key: key.Name, PreviousValue: previous})
+	}
+
+	if len(entries) == 0 {
+		exitWithError(fmt.Errorf("secret %s does not have any keys to rotate", name))
+	}
+
+	// the previous values are logged first, so they are never lost
+	if err := appendRotationLog(entries); err != nil {
+		exitWithError(err)
+	}
+
+	literalChanged := false
+	for n, key := range keys {
+		if err := rotateKey(secret, key, values[n]); err != nil {
+			exitWithError(err)
+		}
+		if key.File == nil && keyVaultRef(key) == "" {
+			literalChanged = true
+		}
+	}
+
+	if literalChanged {
+		writeSecrets(path, doc, yml.Secrets)
+	}
+
+	fmt.Printf("Rotated %s, the previous value is kept in %s\n\nNext steps:\n", name, rotationLogFile)
+	for n, step := range r.FollowUp {
+		fmt.Printf("%d. %s\n", n+1, step)
+	}
+}
+
+// keyValue returns the current value of the key, from the secret for literals, from Vault
+// for Vault references or from the file the value is read from
+func keyValue(key secretKey) (string, error) {
+	if ref := keyVaultRef(key); ref != "" {
+		return resolveVaultRef(ref)
+	}
+
+	if key.File == nil {
+		return key.Value.Value, nil
+	}
+
+	if key.File.ValueCommand != "" {
+		return "", fmt.Errorf("key %s is created by a command, which generates a new value each time ofc-bootstrap is run", key.Name)
+	}
+
+	path, err := homedir.Expand(key.File.ValueFrom)
+	if err != nil {
+		return "", err
+	}
+
+	previous, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	return string(previous), nil
+}
+
+// rotateKey sets the new value of the key, in the secret for literals, in Vault for Vault
+// references or in the file the value is read from
+func rotateKey(secret *types.Secret, key secretKey, value string) error {
+	if ref := keyVaultRef(key); ref != "" {
+		parsed, err := parseVaultRef(ref)
+		if err != nil {
+			return err
+		}
+		return writeVaultRef(parsed, value)
+	}
+
+	if key.File == nil {
+		setLiteral(secret, key.Name, value)
+		return nil
+	}
+
+	path, err := homedir.Expand(key.File.ValueFrom)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(value), 0600)
+}
+
+// appendRotationLog adds the entries to the rotation log, which is only readable by the current user
+func appendRotationLog(entries []rotationEntry) error {
+	log := []rotationEntry{}
+
+	if existing, err := ioutil.ReadFile(rotationLogFile); err == nil {
+		if err := yaml.Unmarshal(existing, &log); err != nil {
+			return fmt.Errorf("%s gave error: %s", rotationLogFile, err.Error())
+		}
+	}
+
+	out, err := yaml.Marshal(append(log, entries...))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(rotationLogFile, out, 0600)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
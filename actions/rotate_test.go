@@ -0,0 +1,65 @@
+package actions
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func Test_rotateKey_Literal(t *testing.T) {
+	secret := types.Secret{Name: "payload-secret", Literals: []types.Literal{{Name: "payload-secret", Value: "old"}}}
+	key := secretKeys(secret)[0]
+
+	previous, err := keyValue(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rotateKey(&secret, key, "new"); err != nil {
+		t.Fatal(err)
+	}
+
+	if previous != "old" || secret.Literals[0].Value != "new" {
+		t.Errorf("want old to be replaced by new, got %s and %s", previous, secret.Literals[0].Value)
+	}
+}
+
+func Test_rotateKey_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ofc-wizard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "webhook-secret")
+	if err := ioutil.WriteFile(path, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	secret := types.Secret{Name: "gitlab-webhook-secret", Files: []types.FileValue{{Name: "gitlab-webhook-secret", ValueFrom: path}}}
+	key := secretKeys(secret)[0]
+
+	previous, err := keyValue(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous != "old" {
+		t.Errorf("want old, got %s", previous)
+	}
+
+	if err := rotateKey(&secret, key, "new"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(path); string(data) != "new" {
+		t.Errorf("want new, got %s", data)
+	}
+}
+
+func Test_keyValue_Command(t *testing.T) {
+	secret := types.Secret{Name: "a", Files: []types.FileValue{{Name: "a", ValueCommand: "openssl rand -hex 16"}}}
+	if _, err := keyValue(secretKeys(secret)[0]); err == nil {
+		t.Error("want an error for a key created by a command")
+	}
+}
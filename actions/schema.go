@@ -0,0 +1,203 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/burtonr/ofc-wizard/types"
+	"gopkg.in/AlecAivazis/survey.v1"
+	"gopkg.in/yaml.v2"
+)
+
+// schema describes the init.yml fields understood by the ofc-bootstrap release that
+// installs a range of OpenFaaS Cloud versions
+type schema struct {
+	Version    string
+	MinVersion string
+	Fields     []string
+}
+
+// schemas lists the known init.yml schemas, oldest first. Only the schema of the ofc-bootstrap
+// init.yml the wizard was written against, for OpenFaaS Cloud 0.9.7, is known. A new schema
+// is added here, citing the ofc-bootstrap release that introduced it, when fields change
+var schemas = []schema{
+	{
+		Version:    "1",
+		MinVersion: "0.9.7",
+		// build_branch, enable_ecr, ecr_config and customers_secret were added to ofc-bootstrap
+		// after 0.9.7, in releases not recorded here, so they are accepted for every version
+		Fields: []string{
+			"orchestration", "secrets", "registry", "root_domain", "ingress", "deployment",
+			"scm", "github", "gitlab", "oauth", "slack", "customers_url", "s3",
+			"enable_oauth", "tls", "tls_config", "enable_dockerfile_lang", "scale_to_zero",
+			"openfaas_cloud_version", "network_policies",
+			"build_branch", "enable_ecr", "ecr_config", "customers_secret",
+		},
+	},
+}
+
+// schemaMarkerField is written into the init.yml to record the schema it was generated for
+var schemaMarkerField = "schema_version"
+
+// supports reports whether the schema includes the field with the given path
+func (s schema) supports(path string) bool {
+	top := strings.SplitN(path, ".", 2)[0]
+	for _, f := range s.Fields {
+		if f == top {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaFor returns the newest schema that supports the given OpenFaaS Cloud version
+func schemaFor(ofcVersion string) (schema, error) {
+	if _, err := parseVersion(ofcVersion); err != nil {
+		return schema{}, err
+	}
+
+	for i := len(schemas) - 1; i >= 0; i-- {
+		if compareVersions(ofcVersion, schemas[i].MinVersion) >= 0 {
+			return schemas[i], nil
+		}
+	}
+	return schema{}, fmt.Errorf("OpenFaaS Cloud version %s is older than the oldest supported version %s", ofcVersion, schemas[0].MinVersion)
+}
+
+// validateVersion is a survey validator for the OpenFaaS Cloud version
+func validateVersion(val interface{}) error {
+	str, ok := val.(string)
+	if !ok {
+		return errors.New("The version must be a string")
+	}
+	_, err := schemaFor(str)
+	return err
+}
+
+// minVersionForFields returns the oldest OpenFaaS Cloud version which supports all of the
+// fields, or an empty string when there are none
+func minVersionForFields(fields []string) string {
+	min := ""
+	for _, f := range fields {
+		if v := minVersionFor(f); min == "" || compareVersions(v, min) > 0 {
+			min = v
+		}
+	}
+	return min
+}
+
+// validateVersionFor returns a survey validator for the OpenFaaS Cloud version, which must
+// also support each of the fields already chosen
+func validateVersionFor(fields []string) survey.Validator {
+	return func(val interface{}) error {
+		if err := validateVersion(val); err != nil {
+			return err
+		}
+
+		version, _ := val.(string)
+		for _, f := range fields {
+			if min := minVersionFor(f); compareVersions(version, min) < 0 {
+				return fmt.Errorf("%s needs OpenFaaS Cloud %s or newer", f, min)
+			}
+		}
+		return nil
+	}
+}
+
+// parseVersion splits a version such as 0.9.7 or v0.9.7 into its numeric parts
+func parseVersion(version string) ([]int, error) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	numbers := make([]int, len(parts))
+
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid version (eg: %s)", version, defaultVersion)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// compareVersions returns -1, 0 or 1 when a is older than, equal to or newer than b.
+// Versions which can not be parsed are treated as 0.0.0
+func compareVersions(a string, b string) int {
+	va, _ := parseVersion(a)
+	vb, _ := parseVersion(b)
+
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+// checkSchema returns an error for each top level field of the init.yml that is set but
+// is not supported by the schema
+func checkSchema(yml types.InitYaml, s schema) []error {
+	out, err := yaml.Marshal(yml)
+	if err != nil {
+		return []error{err}
+	}
+
+	doc := yaml.MapSlice{}
+	if err := yaml.Unmarshal(out, &doc); err != nil {
+		return []error{err}
+	}
+
+	errs := []error{}
+	for _, item := range doc {
+		key := fmt.Sprint(item.Key)
+		if key == schemaMarkerField || s.supports(key) || isZeroValue(item.Value) {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s is not supported by schema version %s (OpenFaaS Cloud %s or newer is required)", key, s.Version, minVersionFor(key)))
+	}
+	return errs
+}
+
+// minVersionFor returns the oldest OpenFaaS Cloud version which supports the field
+func minVersionFor(field string) string {
+	for _, s := range schemas {
+		if s.supports(field) {
+			return s.MinVersion
+		}
+	}
+	return "an unreleased version"
+}
+
+// isZeroValue reports whether the decoded yaml value is empty
+func isZeroValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case yaml.MapSlice:
+		for _, item := range v {
+			if !isZeroValue(item.Value) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
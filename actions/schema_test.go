@@ -0,0 +1,86 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func Test_compareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"0.9.7", "0.10.0", -1},
+		{"v0.10.0", "0.10.0", 0},
+		{"0.12.1", "0.12.0", 1},
+		{"1.0", "0.99.99", 1},
+	}
+
+	for _, c := range cases {
+		if got := compareVersions(c.a, c.b); got != c.want {
+			t.Errorf("compareVersions(%s, %s): want %d, got %d", c.a, c.b, c.want, got)
+		}
+	}
+}
+
+func Test_schemaFor(t *testing.T) {
+	s, err := schemaFor(defaultVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compareVersions(defaultVersion, s.MinVersion) < 0 {
+		t.Errorf("want a schema supporting %s, got %s", defaultVersion, s.Version)
+	}
+
+	for _, version := range []string{"0.8.0", "latest", ""} {
+		if _, err := schemaFor(version); err == nil {
+			t.Errorf("%q: want an error", version)
+		}
+	}
+}
+
+func Test_checkSchema(t *testing.T) {
+	s, _ := schemaFor(defaultVersion)
+
+	yml := types.InitYaml{
+		RootDomain:           "example.com",
+		NetworkPolicies:      true,
+		EnableECR:            true,
+		ECRConfig:            types.ECRConfig{ECRRegion: "eu-west-1"},
+		OpenFaaSCloudVersion: defaultVersion,
+	}
+	if errs := checkSchema(yml, s); len(errs) > 0 {
+		t.Errorf("want no errors, got %v", errs)
+	}
+
+	older := schema{Version: "0", Fields: []string{"root_domain", "openfaas_cloud_version"}}
+	if errs := checkSchema(yml, older); len(errs) != 3 {
+		t.Errorf("want errors for network_policies, enable_ecr and ecr_config, got %v", errs)
+	}
+}
+
+func Test_validateVersionFor(t *testing.T) {
+	validate := validateVersionFor([]string{"enable_ecr"})
+
+	if err := validate(defaultVersion); err != nil {
+		t.Errorf("want no error for %s, got %s", defaultVersion, err)
+	}
+	for _, version := range []string{"0.9.6", "latest"} {
+		if err := validate(version); err == nil {
+			t.Errorf("%q: want an error", version)
+		}
+	}
+}
+
+func Test_minVersionForFields(t *testing.T) {
+	if v := minVersionForFields([]string{"enable_ecr", "root_domain"}); v != schemas[0].MinVersion {
+		t.Errorf("want %s, got %s", schemas[0].MinVersion, v)
+	}
+}
+
+func Test_minVersionForFields_None(t *testing.T) {
+	if v := minVersionForFields(nil); v != "" {
+		t.Errorf("want no version, got %s", v)
+	}
+}
@@ -0,0 +1,71 @@
+package actions
+
+import (
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+var (
+	githubWebhookSecretName = "github-webhook-secret"
+	githubPrivateKeyName    = "private-key"
+)
+
+// webhookSecret returns the webhook secret with the value given, keeping the existing secret
+// when the value is blank, or generating a random value when there is none, which is stored
+// in Vault when OFC_WIZARD_VAULT_PATH is set
+func webhookSecret(secrets []types.Secret, name string, filter string, value string) (types.Secret, error) {
+	if value == "" {
+		if i := findSecret(secrets, name); i >= 0 {
+			return secrets[i], nil
+		}
+
+		generated, err := generateSecretValue()
+		if err != nil {
+			return types.Secret{}, err
+		}
+		if value, err = storeGeneratedValue(name, name, generated); err != nil {
+			return types.Secret{}, err
+		}
+	}
+
+	return types.Secret{
+		Name:      name,
+		Literals:  []types.Literal{{Name: name, Value: value}},
+		Filters:   []string{filter},
+		Namespace: functionsNamespace,
+	}, nil
+}
+
+// githubSecrets returns the webhook secret and, once the app is created, the private key
+// secret for the Github answers
+func githubSecrets(secrets []types.Secret, answers *githubAnswers) ([]types.Secret, error) {
+	webhook, err := webhookSecret(secrets, githubWebhookSecretName, githubFilter, answers.WebhookSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []types.Secret{webhook}
+	if answers.PrivateKeyFrom != "" {
+		result = append(result, types.Secret{
+			Name:      githubPrivateKeyName,
+			Files:     []types.FileValue{{Name: githubPrivateKeyName, ValueFrom: answers.PrivateKeyFrom}},
+			Filters:   []string{githubFilter},
+			Namespace: functionsNamespace,
+		})
+	}
+	return result, nil
+}
+
+// gitlabSecret returns the webhook secret for the GitLab answers, which is read from the
+// file given or has a random value
+func gitlabSecret(secrets []types.Secret, answers *gitlabAnswers) (types.Secret, error) {
+	if answers.WebhookSecret == "" {
+		return webhookSecret(secrets, gitlabWebhookSecretName, gitlabFilter, "")
+	}
+
+	return types.Secret{
+		Name:      gitlabWebhookSecretName,
+		Files:     []types.FileValue{{Name: gitlabWebhookSecretName, ValueFrom: answers.WebhookSecret}},
+		Filters:   []string{gitlabFilter},
+		Namespace: functionsNamespace,
+	}, nil
+}
@@ -0,0 +1,76 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func Test_webhookSecret_GeneratesValue(t *testing.T) {
+	secret, err := webhookSecret(nil, githubWebhookSecretName, githubFilter, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secret.Literals) != 1 || len(secret.Literals[0].Value) != 40 {
+		t.Errorf("want a generated literal, got %+v", secret.Literals)
+	}
+	if secret.Namespace != functionsNamespace {
+		t.Errorf("want namespace %s, got %s", functionsNamespace, secret.Namespace)
+	}
+}
+
+func Test_webhookSecret_KeepsExisting(t *testing.T) {
+	existing := types.Secret{
+		Name:     githubWebhookSecretName,
+		Literals: []types.Literal{{Name: githubWebhookSecretName, Value: "existing"}},
+	}
+
+	secret, err := webhookSecret([]types.Secret{existing}, githubWebhookSecretName, githubFilter, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Literals[0].Value != "existing" {
+		t.Errorf("want the existing value, got %s", secret.Literals[0].Value)
+	}
+}
+
+func Test_githubSecrets(t *testing.T) {
+	answers := &githubAnswers{AppCreated: true, WebhookSecret: "hook", PrivateKeyFrom: "~/private-key.pem"}
+
+	secrets, err := githubSecrets(nil, answers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets) != 2 {
+		t.Fatalf("want 2 secrets, got %d", len(secrets))
+	}
+	if secrets[0].Literals[0].Value != "hook" {
+		t.Errorf("want the webhook secret given, got %s", secrets[0].Literals[0].Value)
+	}
+	if secrets[1].Name != githubPrivateKeyName || secrets[1].Files[0].ValueFrom != "~/private-key.pem" {
+		t.Errorf("want the private key file, got %+v", secrets[1])
+	}
+}
+
+func Test_gitlabSecret_File(t *testing.T) {
+	secret, err := gitlabSecret(nil, &gitlabAnswers{WebhookSecret: "~/gitlab-webhook-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret.Files) != 1 || secret.Files[0].ValueFrom != "~/gitlab-webhook-secret" {
+		t.Errorf("want the secret read from the file, got %+v", secret)
+	}
+}
+
+func Test_orchestratorSecret_Swarm(t *testing.T) {
+	secret := orchestratorSecret(swarm, types.Secret{Name: "a", Namespace: functionsNamespace})
+	if secret.Namespace != "" {
+		t.Errorf("want no namespace on swarm, got %s", secret.Namespace)
+	}
+
+	secret = orchestratorSecret(kubernetes, types.Secret{Name: "a", Namespace: functionsNamespace})
+	if secret.Namespace != functionsNamespace {
+		t.Errorf("want %s on kubernetes, got %s", functionsNamespace, secret.Namespace)
+	}
+}
@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+// generateSecretValue returns a random value suitable for a webhook or payload secret
+func generateSecretValue() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// secretKey is a single value of a secret, along with a description of where it comes from
+type secretKey struct {
+	Name   string
+	Source string
+	Value  types.Literal
+	File   *types.FileValue
+}
+
+// secretKeys returns each literal and file of the secret
+func secretKeys(secret types.Secret) []secretKey {
+	keys := []secretKey{}
+
+	for _, l := range secret.Literals {
+		source := "literal"
+		if isVaultRef(l.Value) {
+			source = "vault " + strings.TrimPrefix(l.Value, vaultScheme)
+		}
+		keys = append(keys, secretKey{Name: l.Name, Source: source, Value: l})
+	}
+
+	for i, f := range secret.Files {
+		source := "file " + f.ValueFrom
+		if isVaultRef(f.ValueFrom) {
+			source = "vault " + strings.TrimPrefix(f.ValueFrom, vaultScheme)
+		} else if f.ValueCommand != "" {
+			source = fmt.Sprintf("command %q, then file %s", f.ValueCommand, f.ValueFrom)
+		}
+		keys = append(keys, secretKey{Name: f.Name, Source: source, File: &secret.Files[i]})
+	}
+	return keys
+}
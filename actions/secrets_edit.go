@@ -0,0 +1,490 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/burtonr/ofc-wizard/types"
+	"gopkg.in/AlecAivazis/survey.v1"
+	"gopkg.in/yaml.v2"
+)
+
+// SecretValues holds the keys, filters and namespace of a secret given on the command line.
+// Literals and files are given as name=value and name=path, commands as name=command,
+// where the command writes a file of the same name
+type SecretValues struct {
+	Literals  []string
+	Files     []string
+	Commands  []string
+	Filters   []string
+	Namespace string
+}
+
+var (
+	literalKeyType = "literal value"
+	fileKeyType    = "file"
+	commandKeyType = "file created by a command"
+	newKeyOption   = "<add a new key>"
+)
+
+func (v SecretValues) hasKeys() bool {
+	return len(v.Literals) > 0 || len(v.Files) > 0 || len(v.Commands) > 0
+}
+
+// apply adds the keys to the secret, replacing any existing key of the same name
+func (v SecretValues) apply(secret *types.Secret) error {
+	for _, l := range v.Literals {
+		name, value, err := splitPair(l)
+		if err != nil {
+			return err
+		}
+		setLiteral(secret, name, value)
+	}
+
+	for _, f := range v.Files {
+		name, path, err := splitPair(f)
+		if err != nil {
+			return err
+		}
+		setFile(secret, types.FileValue{Name: name, ValueFrom: path})
+	}
+
+	for _, c := range v.Commands {
+		name, command, err := splitPair(c)
+		if err != nil {
+			return err
+		}
+		setFile(secret, types.FileValue{Name: name, ValueFrom: name, ValueCommand: command})
+	}
+
+	if len(v.Filters) > 0 {
+		secret.Filters = v.Filters
+	}
+	if v.Namespace != "" {
+		secret.Namespace = v.Namespace
+	}
+	return nil
+}
+
+func splitPair(pair string) (string, string, error) {
+	parts := strings.SplitN(pair, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", fmt.Errorf("invalid value %q, expected name=value", pair)
+	}
+	return parts[0], parts[1], nil
+}
+
+// setLiteral sets the value of the literal, replacing a file of the same name
+func setLiteral(secret *types.Secret, name string, value string) {
+	for i, l := range secret.Literals {
+		if l.Name == name {
+			secret.Literals[i].Value = value
+			return
+		}
+	}
+
+	removeKey(secret, name)
+	secret.Literals = append(secret.Literals, types.Literal{Name: name, Value: value})
+}
+
+// setFile sets the file, replacing a literal of the same name
+func setFile(secret *types.Secret, file types.FileValue) {
+	for i, f := range secret.Files {
+		if f.Name == file.Name {
+			secret.Files[i] = file
+			return
+		}
+	}
+
+	removeKey(secret, file.Name)
+	secret.Files = append(secret.Files, file)
+}
+
+// removeKey removes the literal or file with the name, reporting whether one was found
+func removeKey(secret *types.Secret, name string) bool {
+	found := false
+
+	literals := []types.Literal{}
+	for _, l := range secret.Literals {
+		if l.Name == name {
+			found = true
+			continue
+		}
+		literals = append(literals, l)
+	}
+
+	files := []types.FileValue{}
+	for _, f := range secret.Files {
+		if f.Name == name {
+			found = true
+			continue
+		}
+		files = append(files, f)
+	}
+
+	secret.Literals = literals
+	secret.Files = files
+	return found
+}
+
+func findSecret(secrets []types.Secret, name string) int {
+	for i, s := range secrets {
+		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// putSecret replaces the secret of the same name, or adds it when there is none
+func putSecret(secrets []types.Secret, secret types.Secret) []types.Secret {
+	if i := findSecret(secrets, secret.Name); i >= 0 {
+		secrets[i] = secret
+		return secrets
+	}
+	return append(secrets, secret)
+}
+
+// knownFilters returns every filter ofc-bootstrap understands, in alphabetical order
+func knownFilters() []string {
+	filters := []string{}
+	for f := range requiredSecrets {
+		filters = append(filters, f)
+	}
+	sort.Strings(filters)
+	return filters
+}
+
+// askSecretKey asks for the type and value of a single key of the secret
+func askSecretKey(secret *types.Secret, name string) error {
+	if name == "" {
+		if err := survey.AskOne(&survey.Input{Message: "Enter the name of the key:", Default: secret.Name}, &name, survey.Required); err != nil {
+			return err
+		}
+	}
+
+	keyType := ""
+	typeQuestion := &survey.Select{
+		Message: fmt.Sprintf("Where does the value of %s come from?", name),
+		Options: []string{literalKeyType, fileKeyType, commandKeyType},
+	}
+	if err := survey.AskOne(typeQuestion, &keyType, nil); err != nil {
+		return err
+	}
+
+	switch keyType {
+	case literalKeyType:
+		value := ""
+		valueQuestion := &survey.Password{Message: fmt.Sprintf("Enter the value of %s (leave blank for a random value):", name)}
+		if err := survey.AskOne(valueQuestion, &value, nil); err != nil {
+			return err
+		}
+
+		if value == "" {
+			generated, err := generateSecretValue()
+			if err != nil {
+				return err
+			}
+			if value, err = storeGeneratedValue(secret.Name, name, generated); err != nil {
+				return err
+			}
+		}
+		setLiteral(secret, name, value)
+	case fileKeyType:
+		path := ""
+		if err := survey.AskOne(&survey.Input{Message: fmt.Sprintf("Enter the path of the file for %s:", name)}, &path, survey.Required); err != nil {
+			return err
+		}
+		setFile(secret, types.FileValue{Name: name, ValueFrom: path})
+	case commandKeyType:
+		command := ""
+		commandQuestion := &survey.Input{
+			Message: fmt.Sprintf("Enter the command which creates the file %s:", name),
+			Help:    "The command is run by ofc-bootstrap and must write the value to a file with the same name as the key",
+		}
+		if err := survey.AskOne(commandQuestion, &command, survey.Required); err != nil {
+			return err
+		}
+		setFile(secret, types.FileValue{Name: name, ValueFrom: name, ValueCommand: command})
+	}
+	return nil
+}
+
+// askSecretOptions asks for the filters and namespace of the secret
+func askSecretOptions(secret *types.Secret, orchestration string) error {
+	filters := secret.Filters
+	if len(filters) == 0 {
+		filters = []string{defaultFilter}
+	}
+
+	filterQuestion := &survey.MultiSelect{
+		Message: "Choose the filters which enable the secret:",
+		Options: knownFilters(),
+		Default: filters,
+	}
+	if err := survey.AskOne(filterQuestion, &secret.Filters, nil); err != nil {
+		return err
+	}
+
+	if orchestration == swarm {
+		return nil
+	}
+
+	namespaceQuestion := &survey.Input{
+		Message: "Enter the namespace of the secret (leave blank for the default):",
+		Default: secret.Namespace,
+		Help:    "Secrets are created in the openfaas namespace unless they are read by functions (openfaas-fn) or cert-manager",
+	}
+	return survey.AskOne(namespaceQuestion, &secret.Namespace, nil)
+}
+
+// AddSecret adds a new secret to the init.yml at the path, asking for its keys, filters
+// and namespace when they are not given
+func AddSecret(path string, name string, values SecretValues) {
+	doc, yml := loadInitDoc(path)
+
+	if findSecret(yml.Secrets, name) >= 0 {
+		exitWithError(fmt.Errorf("secret %s already exists, use 'ofc-wizard secrets set' to change it", name))
+	}
+
+	secret := types.Secret{Name: name}
+	if err := values.apply(&secret); err != nil {
+		exitWithError(err)
+	}
+
+	if !values.hasKeys() {
+		for more := true; more; {
+			if err := askSecretKey(&secret, ""); err != nil {
+				exitWithError(err)
+			}
+			if err := survey.AskOne(&survey.Confirm{Message: "Add another key?"}, &more, nil); err != nil {
+				exitWithError(err)
+			}
+		}
+	}
+
+	if len(values.Filters) == 0 {
+		if err := askSecretOptions(&secret, yml.Orchestration); err != nil {
+			exitWithError(err)
+		}
+	}
+
+	writeSecrets(path, doc, append(yml.Secrets, secret))
+}
+
+// SetSecret changes the keys, filters or namespace of an existing secret in the init.yml
+// at the path, asking for a key to change when none are given
+func SetSecret(path string, name string, values SecretValues) {
+	doc, yml := loadInitDoc(path)
+
+	i := findSecret(yml.Secrets, name)
+	if i < 0 {
+		exitWithError(fmt.Errorf("secret %s does not exist, use 'ofc-wizard secrets add' to create it", name))
+	}
+
+	secret := &yml.Secrets[i]
+	if err := values.apply(secret); err != nil {
+		exitWithError(err)
+	}
+
+	if !values.hasKeys() && len(values.Filters) == 0 && values.Namespace == "" {
+		options := []string{}
+		for _, k := range secretKeys(*secret) {
+			options = append(options, k.Name)
+		}
+
+		key := ""
+		keyQuestion := &survey.Select{Message: "Choose the key to change:", Options: append(options, newKeyOption)}
+		if err := survey.AskOne(keyQuestion, &key, nil); err != nil {
+			exitWithError(err)
+		}
+		if key == newKeyOption {
+			key = ""
+		}
+
+		if err := askSecretKey(secret, key); err != nil {
+			exitWithError(err)
+		}
+	}
+
+	writeSecrets(path, doc, yml.Secrets)
+}
+
+// RemoveSecret removes the secret, or only the given key of the secret, from the init.yml at the path
+func RemoveSecret(path string, name string, key string) {
+	doc, yml := loadInitDoc(path)
+
+	i := findSecret(yml.Secrets, name)
+	if i < 0 {
+		exitWithError(fmt.Errorf("secret %s does not exist", name))
+	}
+
+	if key == "" {
+		writeSecrets(path, doc, append(yml.Secrets[:i], yml.Secrets[i+1:]...))
+		return
+	}
+
+	if !removeKey(&yml.Secrets[i], key) {
+		exitWithError(fmt.Errorf("secret %s does not have a key %s", name, key))
+	}
+	writeSecrets(path, doc, yml.Secrets)
+}
+
+// loadInitDoc loads the init.yml at the path both as a document, to write it back with its
+// comments, order and unknown fields intact, and as the wizard's types
+func loadInitDoc(path string) (yamlFile, types.InitYaml) {
+	return loadYamlDoc(path), *LoadInitFileFrom(path)
+}
+
+// writeSecrets replaces the secrets of the document, after checking that the names of the
+// secrets and their keys are unique, and writes it to the path. Only the secrets which
+// changed are edited
+func writeSecrets(path string, file yamlFile, secrets []types.Secret) {
+	if err := checkSecretNames(secrets); err != nil {
+		exitWithError(err)
+	}
+
+	doc := file.Doc
+	if _, ok := getKey(doc, "secrets"); ok || len(secrets) > 0 {
+		doc = setKey(doc, "secrets", secretsDoc(doc, secrets))
+	}
+	writeYamlDoc(path, file, doc)
+}
+
+// The fields of the secrets written by the wizard. Any other fields of the existing secrets
+// are kept
+var (
+	secretFields  = yamlFieldNames(types.Secret{})
+	literalFields = yamlFieldNames(types.Literal{})
+	fileFields    = yamlFieldNames(types.FileValue{})
+)
+
+// secretsDoc converts the secrets into their yaml document form, encrypted when needed. The
+// fields of the secrets, literals and files in the document which the wizard does not know
+// are kept, along with the order of their fields
+func secretsDoc(doc yaml.MapSlice, secrets []types.Secret) []interface{} {
+	out, err := yaml.Marshal(secretsToWrite(secrets))
+	if err != nil {
+		exitWithError(err)
+	}
+
+	items := []yaml.MapSlice{}
+	if err := yaml.Unmarshal(out, &items); err != nil {
+		exitWithError(err)
+	}
+
+	existing, _ := getKey(doc, "secrets")
+	existingList, _ := existing.([]interface{})
+
+	list := []interface{}{}
+	for _, item := range items {
+		if old, ok := namedItem(existingList, item); ok {
+			item = mergeFields(old, item, secretFields)
+			item = mergeNamedList(old, item, "literals", literalFields)
+			item = mergeNamedList(old, item, "files", fileFields)
+		}
+		list = append(list, item)
+	}
+	return list
+}
+
+// namedItem returns the mapping in the list with the same name as the item
+func namedItem(list []interface{}, item yaml.MapSlice) (yaml.MapSlice, bool) {
+	name, ok := getKey(item, "name")
+	if !ok {
+		return nil, false
+	}
+
+	for _, i := range list {
+		m, _ := i.(yaml.MapSlice)
+		if n, ok := getKey(m, "name"); ok && n == name {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// mergeFields returns the fields of the item, in the order of the old item, along with the
+// old fields which are not known
+func mergeFields(old yaml.MapSlice, item yaml.MapSlice, known []string) yaml.MapSlice {
+	merged := yaml.MapSlice{}
+	for _, field := range old {
+		if value, ok := getKey(item, fmt.Sprint(field.Key)); ok {
+			merged = append(merged, yaml.MapItem{Key: field.Key, Value: value})
+		} else if !contains(known, fmt.Sprint(field.Key)) {
+			merged = append(merged, field)
+		}
+	}
+
+	for _, field := range item {
+		if _, ok := getKey(old, fmt.Sprint(field.Key)); !ok {
+			merged = append(merged, field)
+		}
+	}
+	return merged
+}
+
+// mergeNamedList merges the fields of each item of the list held by the key with the old
+// item of the same name
+func mergeNamedList(old yaml.MapSlice, item yaml.MapSlice, key string, known []string) yaml.MapSlice {
+	oldValue, _ := getKey(old, key)
+	oldList, _ := oldValue.([]interface{})
+	value, _ := getKey(item, key)
+	list, ok := value.([]interface{})
+	if !ok || len(oldList) == 0 {
+		return item
+	}
+
+	merged := []interface{}{}
+	for _, i := range list {
+		m, isMap := i.(yaml.MapSlice)
+		if o, found := namedItem(oldList, m); isMap && found {
+			i = mergeFields(o, m, known)
+		}
+		merged = append(merged, i)
+	}
+	return setKey(item, key, merged)
+}
+
+// yamlFieldNames returns the yaml names of the fields of the struct
+func yamlFieldNames(v interface{}) []string {
+	names := []string{}
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = strings.ToLower(t.Field(i).Name)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// checkSecretNames returns an error when a secret name, or a key name within a secret, is used twice
+func checkSecretNames(secrets []types.Secret) error {
+	names := map[string]bool{}
+
+	for _, s := range secrets {
+		if names[s.Name] {
+			return fmt.Errorf("secret %s is defined more than once", s.Name)
+		}
+		names[s.Name] = true
+
+		keys := map[string]bool{}
+		for _, k := range secretKeys(s) {
+			if keys[k.Name] {
+				return fmt.Errorf("secret %s has more than one key named %s", s.Name, k.Name)
+			}
+			keys[k.Name] = true
+		}
+	}
+	return nil
+}
+
+func exitWithError(err error) {
+	fmt.Fprintln(os.Stderr, err.Error())
+	os.Exit(1)
+}
@@ -0,0 +1,212 @@
+package actions
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func Test_SecretValues_apply(t *testing.T) {
+	secret := types.Secret{
+		Name:     "my-secret",
+		Literals: []types.Literal{{Name: "token", Value: "old"}},
+		Files:    []types.FileValue{{Name: "key", ValueFrom: "key.pem"}},
+	}
+	values := SecretValues{
+		Literals:  []string{"token=new=value", "key=literal"},
+		Files:     []string{"cert=~/cert.pem"},
+		Commands:  []string{"generated=openssl rand -out generated 32"},
+		Filters:   []string{"default", "auth"},
+		Namespace: "openfaas-fn",
+	}
+
+	if err := values.apply(&secret); err != nil {
+		t.Fatal(err)
+	}
+
+	want := types.Secret{
+		Name:     "my-secret",
+		Literals: []types.Literal{{Name: "token", Value: "new=value"}, {Name: "key", Value: "literal"}},
+		Files: []types.FileValue{
+			{Name: "cert", ValueFrom: "~/cert.pem"},
+			{Name: "generated", ValueFrom: "generated", ValueCommand: "openssl rand -out generated 32"},
+		},
+		Filters:   []string{"default", "auth"},
+		Namespace: "openfaas-fn",
+	}
+	if !reflect.DeepEqual(secret, want) {
+		t.Errorf("want %+v, got %+v", want, secret)
+	}
+}
+
+func Test_SecretValues_apply_Invalid(t *testing.T) {
+	for _, values := range []SecretValues{
+		{Literals: []string{"token"}},
+		{Files: []string{"=path"}},
+		{Commands: []string{""}},
+	} {
+		if err := values.apply(&types.Secret{}); err == nil {
+			t.Errorf("%+v: want an error", values)
+		}
+	}
+}
+
+func Test_setLiteral(t *testing.T) {
+	secret := types.Secret{Files: []types.FileValue{{Name: "token", ValueFrom: "token.txt"}}}
+
+	setLiteral(&secret, "token", "abc")
+	setLiteral(&secret, "token", "def")
+	if len(secret.Files) != 0 || !reflect.DeepEqual(secret.Literals, []types.Literal{{Name: "token", Value: "def"}}) {
+		t.Errorf("want the file replaced by the literal, got %+v", secret)
+	}
+}
+
+func Test_setFile(t *testing.T) {
+	secret := types.Secret{Literals: []types.Literal{{Name: "key", Value: "abc"}}}
+
+	setFile(&secret, types.FileValue{Name: "key", ValueFrom: "a.pem"})
+	setFile(&secret, types.FileValue{Name: "key", ValueFrom: "b.pem"})
+	if len(secret.Literals) != 0 || !reflect.DeepEqual(secret.Files, []types.FileValue{{Name: "key", ValueFrom: "b.pem"}}) {
+		t.Errorf("want the literal replaced by the file, got %+v", secret)
+	}
+}
+
+func Test_removeKey(t *testing.T) {
+	secret := types.Secret{
+		Literals: []types.Literal{{Name: "a"}, {Name: "b"}},
+		Files:    []types.FileValue{{Name: "c"}},
+	}
+
+	if !removeKey(&secret, "a") || !removeKey(&secret, "c") {
+		t.Error("want the keys found")
+	}
+	if removeKey(&secret, "d") {
+		t.Error("want d not found")
+	}
+	if !reflect.DeepEqual(secret.Literals, []types.Literal{{Name: "b"}}) || len(secret.Files) != 0 {
+		t.Errorf("want only b left, got %+v", secret)
+	}
+}
+
+func Test_checkSecretNames(t *testing.T) {
+	cases := []struct {
+		name    string
+		secrets []types.Secret
+		valid   bool
+	}{
+		{"unique", []types.Secret{{Name: "a", Literals: []types.Literal{{Name: "x"}}}, {Name: "b", Literals: []types.Literal{{Name: "x"}}}}, true},
+		{"secret twice", []types.Secret{{Name: "a"}, {Name: "a"}}, false},
+		{"key twice", []types.Secret{{Name: "a", Literals: []types.Literal{{Name: "x"}}, Files: []types.FileValue{{Name: "x"}}}}, false},
+	}
+
+	for _, c := range cases {
+		if err := checkSecretNames(c.secrets); (err == nil) != c.valid {
+			t.Errorf("%s: want valid %t, got %v", c.name, c.valid, err)
+		}
+	}
+}
+
+// secretsSource is an init.yml with comments and fields unknown to the wizard
+var secretsSource = `# settings for the staging cluster
+orchestration: kubernetes
+secrets:
+# signs requests between functions
+- name: payload-secret
+  literals:
+  - name: payload-secret
+    value: abc # rotated yearly
+  filters:
+  - default
+  namespace: openfaas-fn
+  owner: platform
+- name: basic-auth
+  literals:
+  - name: basic-auth-user
+    value: admin
+    description: the gateway user
+  filters: ["default"]
+root_domain: example.com # the public domain
+`
+
+// editSecrets writes the source to a temporary init.yml, runs the edit and returns the file
+func editSecrets(t *testing.T, edit func(path string)) string {
+	dir, err := ioutil.TempDir("", "ofc-wizard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "init.yml")
+	if err := ioutil.WriteFile(path, []byte(secretsSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	edit(path)
+
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func Test_AddSecret(t *testing.T) {
+	got := editSecrets(t, func(path string) {
+		AddSecret(path, "slack-token", SecretValues{Literals: []string{"token=xoxb"}, Filters: []string{"default"}})
+	})
+
+	want := strings.Replace(secretsSource, "  filters: [\"default\"]\n", "  filters: [\"default\"]\n- name: slack-token\n  literals:\n  - name: token\n    value: xoxb\n  filters:\n  - default\n", 1)
+	if got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func Test_SetSecret(t *testing.T) {
+	got := editSecrets(t, func(path string) {
+		SetSecret(path, "basic-auth", SecretValues{Literals: []string{"basic-auth-user=root"}})
+	})
+
+	want := strings.Replace(secretsSource, "value: admin", "value: root", 1)
+	if got != want {
+		t.Errorf("want only the value changed:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func Test_SetSecret_Filters(t *testing.T) {
+	got := editSecrets(t, func(path string) {
+		SetSecret(path, "payload-secret", SecretValues{Filters: []string{"default", "auth"}})
+	})
+
+	want := strings.Replace(secretsSource, "  - default\n  namespace", "  - default\n  - auth\n  namespace", 1)
+	if got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func Test_RemoveSecret(t *testing.T) {
+	got := editSecrets(t, func(path string) {
+		RemoveSecret(path, "payload-secret", "")
+	})
+
+	want := "# settings for the staging cluster\norchestration: kubernetes\nsecrets:\n" + secretsSource[strings.Index(secretsSource, "- name: basic-auth"):]
+	if got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func Test_RemoveSecret_Key(t *testing.T) {
+	got := editSecrets(t, func(path string) {
+		AddSecret(path, "jwt", SecretValues{Literals: []string{"public=a", "private=b"}, Filters: []string{"auth"}})
+		RemoveSecret(path, "jwt", "public")
+	})
+
+	want := strings.Replace(secretsSource, "  filters: [\"default\"]\n", "  filters: [\"default\"]\n- name: jwt\n  literals:\n  - name: private\n    value: b\n  filters:\n  - auth\n", 1)
+	if got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -0,0 +1,104 @@
+package actions
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+// swarmSecretName returns the docker secret name for the key of the secret. The key is prefixed
+// with the secret name, unless it already starts with it, so keys of the same name in
+// different secrets do not collide
+func swarmSecretName(secret string, key string) string {
+	if key == secret || strings.HasPrefix(key, secret+"-") {
+		return key
+	}
+	return secret + "-" + key
+}
+
+// checkSwarmNames returns an error when two keys would create the same docker secret
+func checkSwarmNames(secrets []types.Secret) error {
+	created := map[string]string{}
+	for _, s := range secrets {
+		for _, key := range secretKeys(s) {
+			name := swarmSecretName(s.Name, key.Name)
+			if from, ok := created[name]; ok {
+				return fmt.Errorf("secret %s and secret %s would both create the docker secret %s", from, s.Name, name)
+			}
+			created[name] = s.Name
+		}
+	}
+	return nil
+}
+
+// listSwarmSecrets prints the docker secret that would be created for each key, without its value
+func listSwarmSecrets(secrets []types.Secret, out io.Writer) error {
+	if err := checkSwarmNames(secrets); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DOCKER SECRET\tFROM SECRET\tSOURCE")
+	for _, s := range secrets {
+		for _, key := range secretKeys(s) {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", swarmSecretName(s.Name, key.Name), s.Name, key.Source)
+		}
+	}
+	return w.Flush()
+}
+
+// writeSwarmScript writes a shell script which creates a docker secret for each key. Docker
+// Swarm secrets hold a single value, so each literal and file becomes its own secret, named by
+// swarmSecretName. Values kept in Vault are read when the script is written
+func writeSwarmScript(secrets []types.Secret, out io.Writer) error {
+	if err := checkSwarmNames(secrets); err != nil {
+		return err
+	}
+
+	lines := []string{
+		"#!/bin/sh",
+		"# Creates the Docker Swarm secrets for OpenFaaS Cloud, generated by ofc-wizard.",
+		"# This script contains secret values, review it and delete it once it has been run.",
+		"set -e",
+	}
+
+	for _, s := range secrets {
+		lines = append(lines, "", "# "+s.Name)
+
+		for _, key := range secretKeys(s) {
+			name := swarmSecretName(s.Name, key.Name)
+			if key.File == nil || isVaultRef(key.File.ValueFrom) {
+				value, err := resolveSecretKey(key)
+				if err != nil {
+					return fmt.Errorf("secret %s: %s", s.Name, err.Error())
+				}
+				lines = append(lines, fmt.Sprintf("printf '%%s' %s | docker secret create %s -", shellQuote(value), shellQuote(name)))
+				continue
+			}
+
+			if key.File.ValueCommand != "" {
+				lines = append(lines, key.File.ValueCommand)
+			}
+			lines = append(lines, fmt.Sprintf("docker secret create %s %s", shellQuote(name), shellPath(key.File.ValueFrom)))
+		}
+	}
+
+	_, err := fmt.Fprintln(out, strings.Join(lines, "\n"))
+	return err
+}
+
+// shellQuote quotes the value for use as a single shell word
+func shellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+// shellPath quotes the path, leaving a leading ~ for the shell to expand
+func shellPath(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		return "~/" + shellQuote(strings.TrimPrefix(path, "~/"))
+	}
+	return shellQuote(path)
+}
@@ -0,0 +1,106 @@
+package actions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func Test_swarmSecretName(t *testing.T) {
+	cases := []struct {
+		secret, key, want string
+	}{
+		{"payload-secret", "payload-secret", "payload-secret"},
+		{"basic-auth", "basic-auth-user", "basic-auth-user"},
+		{"my-secret", "token", "my-secret-token"},
+		{"s3", "access-key", "s3-access-key"},
+	}
+
+	for _, c := range cases {
+		if got := swarmSecretName(c.secret, c.key); got != c.want {
+			t.Errorf("swarmSecretName(%s, %s): want %s, got %s", c.secret, c.key, c.want, got)
+		}
+	}
+}
+
+func Test_writeSwarmScript(t *testing.T) {
+	secrets := []types.Secret{
+		{
+			Name: "basic-auth",
+			Literals: []types.Literal{
+				{Name: "basic-auth-user", Value: "admin"},
+				{Name: "basic-auth-password", Value: "it's secret"},
+			},
+		},
+		{
+			Name:  "private-key",
+			Files: []types.FileValue{{Name: "private-key", ValueFrom: "~/keys/app key.pem"}},
+		},
+		{
+			Name:     "other",
+			Literals: []types.Literal{{Name: "token", Value: "abc"}},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	if err := writeSwarmScript(secrets, out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`printf '%s' 'admin' | docker secret create 'basic-auth-user' -`,
+		`printf '%s' 'it'\''s secret' | docker secret create 'basic-auth-password' -`,
+		`docker secret create 'private-key' ~/'keys/app key.pem'`,
+		`printf '%s' 'abc' | docker secret create 'other-token' -`,
+	} {
+		if !strings.Contains(out.String(), want+"\n") {
+			t.Errorf("want the line %s, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func Test_writeSwarmScript_Duplicates(t *testing.T) {
+	secrets := []types.Secret{
+		{Name: "a", Literals: []types.Literal{{Name: "b-c", Value: "1"}}},
+		{Name: "a-b", Literals: []types.Literal{{Name: "c", Value: "2"}}},
+	}
+
+	if err := writeSwarmScript(secrets, &bytes.Buffer{}); err == nil {
+		t.Error("want an error when two keys create the docker secret a-b-c")
+	}
+	if err := listSwarmSecrets(secrets, &bytes.Buffer{}); err == nil {
+		t.Error("want an error listing the duplicate docker secret")
+	}
+}
+
+func Test_shellQuote(t *testing.T) {
+	cases := map[string]string{
+		"":          `''`,
+		"value":     `'value'`,
+		"it's":      `'it'\''s'`,
+		"$(rm -rf)": `'$(rm -rf)'`,
+	}
+
+	for value, want := range cases {
+		if got := shellQuote(value); got != want {
+			t.Errorf("shellQuote(%q): want %s, got %s", value, want, got)
+		}
+	}
+}
+
+func Test_shellPath(t *testing.T) {
+	cases := map[string]string{
+		"~/key.pem":        `~/'key.pem'`,
+		"/etc/key.pem":     `'/etc/key.pem'`,
+		"~user/key.pem":    `'~user/key.pem'`,
+		"./my dir/key.pem": `'./my dir/key.pem'`,
+	}
+
+	for path, want := range cases {
+		if got := shellPath(path); got != want {
+			t.Errorf("shellPath(%q): want %s, got %s", path, want, got)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+// templateRefPattern matches the branch or tag given after the '#' of a template repository
+var templateRefPattern = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+
+// parseTemplateRepo checks the custom template repository, a http or https URL of a git repository
+// with an optional branch or tag after a '#', and returns it without a trailing slash
+func parseTemplateRepo(raw string) (string, error) {
+	repo, ref := strings.TrimSpace(raw), ""
+	if i := strings.Index(repo, "#"); i >= 0 {
+		repo, ref = repo[:i], repo[i+1:]
+		if ref == "" || !templateRefPattern.MatchString(ref) || strings.Contains(ref, "..") || strings.HasPrefix(ref, "-") {
+			return "", fmt.Errorf("%q does not give a valid branch or tag after the '#'", raw)
+		}
+	}
+
+	u, err := url.Parse(repo)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("%q must be the https URL of a git repository (eg: https://github.com/openfaas-incubator/golang-http-template)", raw)
+	}
+	if u.RawQuery != "" || u.User != nil {
+		return "", fmt.Errorf("%q must not include a query or login", raw)
+	}
+
+	path := strings.Trim(u.Path, "/")
+	if len(strings.Split(path, "/")) < 2 {
+		return "", fmt.Errorf("%q must include the owner and name of the repository", raw)
+	}
+
+	normalised := u.Scheme + "://" + strings.ToLower(u.Host) + "/" + path
+	if ref != "" {
+		normalised += "#" + ref
+	}
+	return normalised, nil
+}
+
+// templateRepoKey identifies a template repository regardless of case, scheme and a .git suffix
+func templateRepoKey(repo string) string {
+	u, _ := url.Parse(repo)
+	return strings.ToLower(u.Host + strings.TrimSuffix(u.Path, ".git") + "#" + u.Fragment)
+}
+
+// customTemplates checks the template repositories, returning them with any duplicates
+// removed, along with an error for each invalid repository
+func customTemplates(repos []string) ([]string, []string, []error) {
+	templates := []string{}
+	duplicates := []string{}
+	errs := []error{}
+	seen := map[string]bool{}
+
+	for _, r := range repos {
+		repo, err := parseTemplateRepo(r)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if seen[templateRepoKey(repo)] {
+			duplicates = append(duplicates, r)
+			continue
+		}
+		seen[templateRepoKey(repo)] = true
+		templates = append(templates, repo)
+	}
+	return templates, duplicates, errs
+}
+
+// validateCustomTemplates is a survey validator for the comma separated template repositories
+func validateCustomTemplates(val interface{}) error {
+	str, ok := val.(string)
+	if !ok {
+		return errors.New("The custom templates must be a string")
+	}
+
+	if _, _, errs := customTemplates(splitList(str)); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// isDockerfileTemplate reports whether the repository looks like it provides the dockerfile template
+func isDockerfileTemplate(repo string) bool {
+	return strings.Contains(strings.ToLower(repo), "dockerfile")
+}
+
+// dockerfileTemplateWarnings returns a warning for each Dockerfile based repository listed
+// while the Dockerfile template is disabled
+func dockerfileTemplateWarnings(templates []string, useDockerfile bool) []string {
+	warnings := []string{}
+	if useDockerfile {
+		return warnings
+	}
+
+	for _, t := range templates {
+		if isDockerfileTemplate(t) {
+			warnings = append(warnings, fmt.Sprintf("%s looks like a Dockerfile template, but the Dockerfile template is not enabled so functions using it will not be built", t))
+		}
+	}
+	return warnings
+}
+
+// checkCustomTemplates returns an error for each invalid or repeated custom template repository
+func checkCustomTemplates(yml types.InitYaml) []error {
+	errs := []error{}
+	_, duplicates, repoErrs := customTemplates(yml.Deployment.CustomTemplates)
+	for _, e := range repoErrs {
+		errs = append(errs, fmt.Errorf("deployment.custom_templates: %s", e.Error()))
+	}
+	for _, d := range duplicates {
+		errs = append(errs, fmt.Errorf("deployment.custom_templates: %s is listed more than once", d))
+	}
+	return errs
+}
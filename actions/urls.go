@@ -0,0 +1,77 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+// endpointScheme returns the scheme OpenFaaS Cloud is served with, https when TLS is enabled
+func endpointScheme(yml types.InitYaml) string {
+	if yml.TLS {
+		return "https"
+	}
+	return "http"
+}
+
+// systemURL returns the URL of the path on the system endpoint, eg: https://system.example.com/gitlab-event
+func systemURL(yml types.InitYaml, path string) string {
+	return fmt.Sprintf("%s://system.%s/%s", endpointScheme(yml), yml.RootDomain, path)
+}
+
+// authURL returns the URL of the OAuth login, which is the callback URL of the OAuth application
+func authURL(yml types.InitYaml) string {
+	return fmt.Sprintf("%s://auth.system.%s/", endpointScheme(yml), yml.RootDomain)
+}
+
+// oauthAppSetting is a setting to enter when creating the OAuth App
+type oauthAppSetting struct {
+	Name  string
+	Value string
+}
+
+// oauthAppSettings returns the settings of the OAuth App for the source control management
+// of the init.yml, along with the page it is created on
+func oauthAppSettings(yml types.InitYaml) (string, []oauthAppSetting) {
+	if yml.SCM == gitlab {
+		page := "Admin Area > Applications"
+		if yml.GitLab.GitLabInstance != "" {
+			page = fmt.Sprintf("%s (%sadmin/applications)", page, normaliseGitLabInstance(yml.GitLab.GitLabInstance))
+		}
+
+		return page, []oauthAppSetting{
+			{Name: "Name", Value: "OpenFaaS Cloud"},
+			{Name: "Redirect URI", Value: authURL(yml)},
+			{Name: "Scopes", Value: strings.Join(gitlabOAuthScopes, ", ")},
+		}
+	}
+
+	return "Settings > Developer settings > OAuth Apps (https://github.com/settings/developers)", []oauthAppSetting{
+		{Name: "Application name", Value: "OpenFaaS Cloud"},
+		{Name: "Homepage URL", Value: systemURL(yml, "")},
+		{Name: "Authorization callback URL", Value: authURL(yml)},
+	}
+}
+
+// printOAuthAppSettings prints the settings to create the OAuth App with
+func printOAuthAppSettings(yml types.InitYaml) {
+	page, settings := oauthAppSettings(yml)
+	fmt.Printf("Create the OAuth App in %s with these settings:\n\n", page)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, s := range settings {
+		fmt.Fprintf(w, "  %s:\t%s\n", s.Name, s.Value)
+	}
+	w.Flush()
+
+	switch {
+	case !yml.TLS:
+		fmt.Println("\nThe URLs use http as TLS is not enabled, update them if TLS is added later")
+	case yml.Ingress == "host":
+		fmt.Printf("\nauth.system.%s must resolve to the node running the ingress controller\n", yml.RootDomain)
+	}
+	fmt.Println()
+}
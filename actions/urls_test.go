@@ -0,0 +1,67 @@
+package actions
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+func Test_oauthAppSettings_GitHub(t *testing.T) {
+	cases := []struct {
+		tls      bool
+		homepage string
+		callback string
+	}{
+		{true, "https://system.example.com/", "https://auth.system.example.com/"},
+		{false, "http://system.example.com/", "http://auth.system.example.com/"},
+	}
+
+	for _, c := range cases {
+		yml := types.InitYaml{RootDomain: "example.com", SCM: github, TLS: c.tls}
+
+		page, settings := oauthAppSettings(yml)
+		if page != "Settings > Developer settings > OAuth Apps (https://github.com/settings/developers)" {
+			t.Errorf("tls %t: unexpected page %s", c.tls, page)
+		}
+
+		want := []oauthAppSetting{
+			{Name: "Application name", Value: "OpenFaaS Cloud"},
+			{Name: "Homepage URL", Value: c.homepage},
+			{Name: "Authorization callback URL", Value: c.callback},
+		}
+		if !reflect.DeepEqual(settings, want) {
+			t.Errorf("tls %t: want %v, got %v", c.tls, want, settings)
+		}
+	}
+}
+
+func Test_oauthAppSettings_GitLab(t *testing.T) {
+	cases := []struct {
+		tls      bool
+		instance string
+		page     string
+		callback string
+	}{
+		{true, "https://gitlab.example.com", "Admin Area > Applications (https://gitlab.example.com/admin/applications)", "https://auth.system.example.com/"},
+		{false, "", "Admin Area > Applications", "http://auth.system.example.com/"},
+	}
+
+	for _, c := range cases {
+		yml := types.InitYaml{RootDomain: "example.com", SCM: gitlab, TLS: c.tls, GitLab: types.GitLab{GitLabInstance: c.instance}}
+
+		page, settings := oauthAppSettings(yml)
+		if page != c.page {
+			t.Errorf("tls %t: want the page %s, got %s", c.tls, c.page, page)
+		}
+
+		want := []oauthAppSetting{
+			{Name: "Name", Value: "OpenFaaS Cloud"},
+			{Name: "Redirect URI", Value: c.callback},
+			{Name: "Scopes", Value: "openid, read_user"},
+		}
+		if !reflect.DeepEqual(settings, want) {
+			t.Errorf("tls %t: want %v, got %v", c.tls, want, settings)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+// validateInitYaml returns an error for each problem with the init.yml
+func validateInitYaml(yml types.InitYaml) []error {
+	errs := []error{}
+
+	if err := checkRootDomain(yml.RootDomain); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := parseRegistry(yml.Registry); err != nil {
+		errs = append(errs, err)
+	}
+
+	version := yml.OpenFaaSCloudVersion
+	if version == "" {
+		version = defaultVersion
+	}
+
+	if s, err := schemaFor(version); err != nil {
+		errs = append(errs, err)
+	} else {
+		errs = append(errs, checkSchema(yml, s)...)
+	}
+
+	errs = append(errs, checkGitLab(yml)...)
+	errs = append(errs, checkECRConfig(yml)...)
+	errs = append(errs, checkAudit(yml)...)
+	errs = append(errs, checkCustomersConfig(yml)...)
+	errs = append(errs, checkCustomTemplates(yml)...)
+	errs = append(errs, checkOrchestrator(yml)...)
+	return append(errs, checkVaultRefs(yml.Secrets)...)
+}
+
+// ValidateInitFile checks the init.yml at the path, exiting with an error when it is invalid
+func ValidateInitFile(path string) {
+	errs := validateInitYaml(*LoadInitFileFrom(path))
+
+	if len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Fprintln(os.Stderr, e.Error())
+		}
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s is valid\n", path)
+}
@@ -0,0 +1,269 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+// Secret values may be kept in the Vault KV secrets engine by using a reference of the form
+// vault://<path>#<key> as a literal value or value_from. The path is the API path of the
+// secret, eg: vault://secret/data/openfaas-cloud#payload-secret for the KV version 2 engine
+// mounted at secret/, or vault://kv/openfaas-cloud#payload-secret for version 1
+var (
+	vaultScheme    = "vault://"
+	vaultAddrEnv   = "VAULT_ADDR"
+	vaultTokenEnv  = "VAULT_TOKEN"
+	vaultPathEnv   = envPrefix + "VAULT_PATH"
+	vaultKVv2Path  = "/data/"
+	vaultAPIPrefix = "/v1/"
+)
+
+// vaultHTTPClient is used for every request to Vault
+var vaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// errVaultNotFound is returned when the path does not exist in Vault
+var errVaultNotFound = errors.New("not found")
+
+// vaultRef is a reference to a single key of a secret in Vault
+type vaultRef struct {
+	Path string
+	Key  string
+}
+
+func (r vaultRef) String() string {
+	return vaultScheme + r.Path + "#" + r.Key
+}
+
+func isVaultRef(value string) bool {
+	return strings.HasPrefix(value, vaultScheme)
+}
+
+// parseVaultRef parses a vault://<path>#<key> reference
+func parseVaultRef(value string) (vaultRef, error) {
+	parts := strings.SplitN(strings.TrimPrefix(value, vaultScheme), "#", 2)
+	if len(parts) != 2 || strings.Trim(parts[0], "/") == "" || parts[1] == "" {
+		return vaultRef{}, fmt.Errorf("invalid Vault reference %q, expected %s<path>#<key>", value, vaultScheme)
+	}
+	return vaultRef{Path: strings.Trim(parts[0], "/"), Key: parts[1]}, nil
+}
+
+// vaultClient reads and writes secrets with the Vault HTTP API
+type vaultClient struct {
+	Address string
+	Token   string
+	HTTP    *http.Client
+}
+
+// newVaultClient returns a client for the Vault given by VAULT_ADDR and VAULT_TOKEN
+func newVaultClient() (*vaultClient, error) {
+	address := os.Getenv(vaultAddrEnv)
+	if address == "" {
+		return nil, fmt.Errorf("%s must be set to use values from Vault", vaultAddrEnv)
+	}
+
+	token := os.Getenv(vaultTokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("%s must be set to use values from Vault", vaultTokenEnv)
+	}
+
+	return &vaultClient{Address: strings.TrimSuffix(address, "/"), Token: token, HTTP: vaultHTTPClient}, nil
+}
+
+// do sends the request to the Vault API, decoding the data of the response into out
+func (c *vaultClient) do(method string, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, c.Address+vaultAPIPrefix+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", c.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return errVaultNotFound
+	case res.StatusCode >= 300:
+		apiErr := struct {
+			Errors []string `json:"errors"`
+		}{}
+		if json.Unmarshal(resBody, &apiErr) == nil && len(apiErr.Errors) > 0 {
+			return fmt.Errorf("Vault returned %s: %s", res.Status, strings.Join(apiErr.Errors, ", "))
+		}
+		return fmt.Errorf("Vault returned %s", res.Status)
+	}
+
+	if out == nil || len(resBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resBody, out)
+}
+
+// read returns the key/value pairs of the secret at the path, and whether it is stored in
+// a KV version 2 engine
+func (c *vaultClient) read(path string) (map[string]interface{}, bool, error) {
+	res := struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	if err := c.do(http.MethodGet, path, nil, &res); err != nil {
+		return nil, false, err
+	}
+
+	// KV version 2 nests the values alongside the metadata of the version
+	if data, ok := res.Data["data"].(map[string]interface{}); ok {
+		if _, ok := res.Data["metadata"]; ok {
+			return data, true, nil
+		}
+	}
+	return res.Data, false, nil
+}
+
+// write sets the key of the secret at the path, keeping its other keys
+func (c *vaultClient) write(ref vaultRef, value string) error {
+	data, kv2, err := c.read(ref.Path)
+	if err == errVaultNotFound {
+		data, kv2 = map[string]interface{}{}, strings.Contains("/"+ref.Path, vaultKVv2Path)
+	} else if err != nil {
+		return err
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	data[ref.Key] = value
+
+	var body interface{} = data
+	if kv2 {
+		body = map[string]interface{}{"data": data}
+	}
+	return c.do(http.MethodPost, ref.Path, body, nil)
+}
+
+// vaultSecrets caches the secrets read from Vault, by path
+var vaultSecrets = map[string]map[string]interface{}{}
+
+// resolveVaultRef returns the value the reference points to, reading each path from Vault once
+func resolveVaultRef(value string) (string, error) {
+	ref, err := parseVaultRef(value)
+	if err != nil {
+		return "", err
+	}
+
+	data, ok := vaultSecrets[ref.Path]
+	if !ok {
+		client, err := newVaultClient()
+		if err != nil {
+			return "", err
+		}
+
+		if data, _, err = client.read(ref.Path); err != nil {
+			return "", fmt.Errorf("unable to read %s from Vault: %s", ref.Path, err.Error())
+		}
+		vaultSecrets[ref.Path] = data
+	}
+
+	v, ok := data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("Vault secret %s does not have a key %s", ref.Path, ref.Key)
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return "", fmt.Errorf("Vault secret %s key %s is not a string", ref.Path, ref.Key)
+}
+
+// writeVaultRef stores the value in Vault at the reference
+func writeVaultRef(ref vaultRef, value string) error {
+	client, err := newVaultClient()
+	if err != nil {
+		return err
+	}
+
+	if err := client.write(ref, value); err != nil {
+		return fmt.Errorf("unable to write %s to Vault: %s", ref.Path, err.Error())
+	}
+	delete(vaultSecrets, ref.Path)
+	return nil
+}
+
+// storeGeneratedValue returns the value to keep in the init.yml for a generated value.
+// When OFC_WIZARD_VAULT_PATH is set the value is written to Vault under the path, keyed
+// by the secret and key names, and a reference to it is returned instead
+func storeGeneratedValue(secret string, key string, value string) (string, error) {
+	path := strings.Trim(os.Getenv(vaultPathEnv), "/")
+	if path == "" {
+		return value, nil
+	}
+
+	ref := vaultRef{Path: path, Key: secret + "." + key}
+	if secret == key {
+		ref.Key = key
+	}
+
+	if err := writeVaultRef(ref, value); err != nil {
+		return "", err
+	}
+	fmt.Printf("Stored the value of %s in Vault at %s\n", key, ref.String())
+	return ref.String(), nil
+}
+
+// keyVaultRef returns the Vault reference the value of the key is kept in, if any
+func keyVaultRef(key secretKey) string {
+	value := key.Value.Value
+	if key.File != nil {
+		value = key.File.ValueFrom
+	}
+
+	if isVaultRef(value) {
+		return value
+	}
+	return ""
+}
+
+// checkVaultRefs returns an error for each Vault reference in the secrets which can not be resolved
+func checkVaultRefs(secrets []types.Secret) []error {
+	errs := []error{}
+
+	for _, s := range secrets {
+		for _, key := range secretKeys(s) {
+			value := keyVaultRef(key)
+			if value == "" {
+				continue
+			}
+
+			if _, err := resolveVaultRef(value); err != nil {
+				errs = append(errs, fmt.Errorf("secret %s key %s: %s", s.Name, key.Name, err.Error()))
+			}
+		}
+	}
+	return errs
+}
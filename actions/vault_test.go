@@ -0,0 +1,226 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/burtonr/ofc-wizard/types"
+)
+
+var testVaultToken = "test-token"
+
+// fakeVault is an in memory Vault KV engine, paths containing /data/ are version 2
+type fakeVault struct {
+	secrets map[string]map[string]interface{}
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != testVaultToken {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, vaultAPIPrefix)
+	kv2 := strings.Contains("/"+path, vaultKVv2Path)
+
+	switch r.Method {
+	case http.MethodGet:
+		data, ok := v.secrets[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+
+		var res interface{} = map[string]interface{}{"data": data}
+		if kv2 {
+			res = map[string]interface{}{"data": map[string]interface{}{
+				"data":     data,
+				"metadata": map[string]interface{}{"version": 1},
+			}}
+		}
+		json.NewEncoder(w).Encode(res)
+	case http.MethodPost:
+		body := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if kv2 {
+			body, _ = body["data"].(map[string]interface{})
+		}
+		v.secrets[path] = body
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// useFakeVault points VAULT_ADDR at a fake Vault holding the secrets, returning a function
+// which restores the environment
+func useFakeVault(secrets map[string]map[string]interface{}) (*fakeVault, func()) {
+	vault := &fakeVault{secrets: secrets}
+	server := httptest.NewServer(vault)
+
+	addr, token := os.Getenv(vaultAddrEnv), os.Getenv(vaultTokenEnv)
+	os.Setenv(vaultAddrEnv, server.URL)
+	os.Setenv(vaultTokenEnv, testVaultToken)
+	vaultSecrets = map[string]map[string]interface{}{}
+
+	return vault, func() {
+		server.Close()
+		os.Setenv(vaultAddrEnv, addr)
+		os.Setenv(vaultTokenEnv, token)
+		vaultSecrets = map[string]map[string]interface{}{}
+	}
+}
+
+func Test_parseVaultRef(t *testing.T) {
+	ref, err := parseVaultRef("vault://secret/data/ofc/#payload-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Path != "secret/data/ofc" || ref.Key != "payload-secret" {
+		t.Errorf("unexpected reference %+v", ref)
+	}
+
+	for _, invalid := range []string{"vault://secret/data/ofc", "vault://#key", "vault://secret/ofc#"} {
+		if _, err := parseVaultRef(invalid); err == nil {
+			t.Errorf("want an error for %s", invalid)
+		}
+	}
+}
+
+func Test_resolveVaultRef_KVv1(t *testing.T) {
+	_, restore := useFakeVault(map[string]map[string]interface{}{
+		"kv/ofc": {"payload-secret": "v1 value"},
+	})
+	defer restore()
+
+	value, err := resolveVaultRef("vault://kv/ofc#payload-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "v1 value" {
+		t.Errorf("want v1 value, got %s", value)
+	}
+}
+
+func Test_resolveVaultRef_KVv2(t *testing.T) {
+	_, restore := useFakeVault(map[string]map[string]interface{}{
+		"secret/data/ofc": {"payload-secret": "v2 value"},
+	})
+	defer restore()
+
+	value, err := resolveVaultRef("vault://secret/data/ofc#payload-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "v2 value" {
+		t.Errorf("want v2 value, got %s", value)
+	}
+}
+
+func Test_resolveVaultRef_NotFound(t *testing.T) {
+	_, restore := useFakeVault(map[string]map[string]interface{}{})
+	defer restore()
+
+	if _, err := resolveVaultRef("vault://secret/data/missing#key"); err == nil {
+		t.Error("want an error for a missing path")
+	}
+}
+
+func Test_resolveVaultRef_PermissionDenied(t *testing.T) {
+	_, restore := useFakeVault(map[string]map[string]interface{}{"kv/ofc": {"a": "b"}})
+	defer restore()
+	os.Setenv(vaultTokenEnv, "wrong")
+
+	_, err := resolveVaultRef("vault://kv/ofc#a")
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("want the Vault error, got %v", err)
+	}
+}
+
+func Test_writeVaultRef_MergesKeys(t *testing.T) {
+	for _, path := range []string{"kv/ofc", "secret/data/ofc"} {
+		vault, restore := useFakeVault(map[string]map[string]interface{}{
+			path: {"existing": "kept"},
+		})
+
+		if err := writeVaultRef(vaultRef{Path: path, Key: "new"}, "added"); err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]interface{}{"existing": "kept", "new": "added"}
+		if got := vault.secrets[path]; len(got) != 2 || got["existing"] != want["existing"] || got["new"] != want["new"] {
+			t.Errorf("%s: want %v, got %v", path, want, got)
+		}
+		restore()
+	}
+}
+
+func Test_writeVaultRef_NewPath(t *testing.T) {
+	vault, restore := useFakeVault(map[string]map[string]interface{}{})
+	defer restore()
+
+	if err := writeVaultRef(vaultRef{Path: "secret/data/new", Key: "a"}, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if vault.secrets["secret/data/new"]["a"] != "b" {
+		t.Errorf("want the new secret, got %v", vault.secrets)
+	}
+
+	// the cached value is replaced after writing
+	if value, err := resolveVaultRef("vault://secret/data/new#a"); err != nil || value != "b" {
+		t.Errorf("want b, got %s %v", value, err)
+	}
+}
+
+func Test_storeGeneratedValue(t *testing.T) {
+	vault, restore := useFakeVault(map[string]map[string]interface{}{})
+	defer restore()
+
+	path := os.Getenv(vaultPathEnv)
+	os.Setenv(vaultPathEnv, "secret/data/ofc")
+	defer os.Setenv(vaultPathEnv, path)
+
+	ref, err := storeGeneratedValue("basic-auth", "basic-auth-password", "generated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != "vault://secret/data/ofc#basic-auth.basic-auth-password" {
+		t.Errorf("unexpected reference %s", ref)
+	}
+	if vault.secrets["secret/data/ofc"]["basic-auth.basic-auth-password"] != "generated" {
+		t.Errorf("want the value in Vault, got %v", vault.secrets)
+	}
+}
+
+func Test_storeGeneratedValue_WithoutVault(t *testing.T) {
+	path := os.Getenv(vaultPathEnv)
+	os.Unsetenv(vaultPathEnv)
+	defer os.Setenv(vaultPathEnv, path)
+
+	value, err := storeGeneratedValue("payload-secret", "payload-secret", "generated")
+	if err != nil || value != "generated" {
+		t.Errorf("want the value itself, got %s %v", value, err)
+	}
+}
+
+func Test_checkVaultRefs(t *testing.T) {
+	_, restore := useFakeVault(map[string]map[string]interface{}{
+		"secret/data/ofc": {"payload-secret": "value"},
+	})
+	defer restore()
+
+	secrets := []types.Secret{
+		{Name: "payload-secret", Literals: []types.Literal{{Name: "payload-secret", Value: "vault://secret/data/ofc#payload-secret"}}},
+		{Name: "basic-auth", Literals: []types.Literal{{Name: "basic-auth-password", Value: "vault://secret/data/ofc#missing"}}},
+		{Name: "private-key", Files: []types.FileValue{{Name: "private-key", ValueFrom: "vault://secret/data/other#private-key"}}},
+		{Name: "plain", Literals: []types.Literal{{Name: "plain", Value: "not a reference"}}},
+	}
+
+	if errs := checkVaultRefs(secrets); len(errs) != 2 {
+		t.Errorf("want 2 errors, got %v", errs)
+	}
+}
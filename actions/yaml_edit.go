@@ -0,0 +1,609 @@
+package actions
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// errNotEditable is returned when a change can not be made in place, so the block holding it
+// is written again instead
+var errNotEditable = errors.New("the yaml can not be edited in place")
+
+// yamlEdit replaces the bytes of the source from start to end with the text
+type yamlEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+// yamlEditor works out the edits which change the source into a new document. Everything
+// which has not changed keeps its comments, order, quoting and layout
+type yamlEditor struct {
+	source []byte
+	// lineStarts holds the offset of the start of each line, followed by the length of the source
+	lineStarts []int
+	edits      []yamlEdit
+	// indentedSequences is set when the source indents sequences below their key, instead
+	// of the compact style written by yaml.v2
+	indentedSequences bool
+}
+
+// editYaml returns the source edited to hold the values of the document. The whole document
+// is written again when the source is not a block mapping or uses anchors
+func editYaml(source []byte, doc yaml.MapSlice) ([]byte, error) {
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// the document is compared with the source in the form yaml.v2 decodes it
+	want := yaml.MapSlice{}
+	if err := yaml.Unmarshal(out, &want); err != nil {
+		return nil, err
+	}
+
+	old := yaml.MapSlice{}
+	root := yaml3.Node{}
+	if yaml.Unmarshal(source, &old) != nil || yaml3.Unmarshal(source, &root) != nil {
+		return out, nil
+	}
+	if len(root.Content) != 1 || !isBlock(root.Content[0], yaml3.MappingNode) || !editable(root.Content[0]) {
+		return out, nil
+	}
+
+	if !bytes.HasSuffix(source, []byte("\n")) {
+		source = append(append([]byte{}, source...), '\n')
+	}
+
+	e := newYamlEditor(source)
+	e.indentedSequences = hasIndentedSequences(root.Content[0])
+	if err := e.mapping(root.Content[0], old, want, e.lineCount()+1, false); err != nil {
+		return out, nil
+	}
+	return e.apply()
+}
+
+func newYamlEditor(source []byte) *yamlEditor {
+	e := &yamlEditor{source: source, lineStarts: []int{0}}
+	for i, b := range source {
+		if b == '\n' {
+			e.lineStarts = append(e.lineStarts, i+1)
+		}
+	}
+	if last := e.lineStarts[len(e.lineStarts)-1]; last != len(source) {
+		e.lineStarts = append(e.lineStarts, len(source))
+	}
+	return e
+}
+
+// isBlock reports whether the node is of the kind and written in block style
+func isBlock(node *yaml3.Node, kind yaml3.Kind) bool {
+	return node.Kind == kind && node.Style&yaml3.FlowStyle == 0
+}
+
+// editable reports whether the node and its children can be edited in place, which needs
+// each value to appear once in the source
+func editable(node *yaml3.Node) bool {
+	if node.Kind == yaml3.AliasNode || node.Anchor != "" || node.Tag == "!!merge" {
+		return false
+	}
+	for _, child := range node.Content {
+		if !editable(child) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasIndentedSequences reports whether the first block sequence held by a mapping is
+// indented below its key
+func hasIndentedSequences(node *yaml3.Node) bool {
+	found, indented := findSequenceStyle(node)
+	return found && indented
+}
+
+func findSequenceStyle(node *yaml3.Node) (bool, bool) {
+	for i, child := range node.Content {
+		if node.Kind == yaml3.MappingNode && i%2 == 1 && isBlock(child, yaml3.SequenceNode) && len(child.Content) > 0 {
+			// items are written after a "- ", so the dash of a compact sequence is two columns
+			// before the item, at the column of the key
+			return true, child.Content[0].Column-2 > node.Content[i-1].Column
+		}
+		if found, indented := findSequenceStyle(child); found {
+			return found, indented
+		}
+	}
+	return false, false
+}
+
+func (e *yamlEditor) lineCount() int {
+	return len(e.lineStarts) - 1
+}
+
+// lineStart returns the offset of the start of the line, numbered from 1
+func (e *yamlEditor) lineStart(line int) int {
+	return e.lineStarts[line-1]
+}
+
+func (e *yamlEditor) lineText(line int) string {
+	return strings.TrimRight(string(e.source[e.lineStarts[line-1]:e.lineStarts[line]]), "\r\n")
+}
+
+// isFiller reports whether the line is blank or only a comment
+func (e *yamlEditor) isFiller(line int) bool {
+	text := strings.TrimSpace(e.lineText(line))
+	return text == "" || strings.HasPrefix(text, "#")
+}
+
+// contentEnd returns the line after the last line of the block from the first line up to the
+// end line which is not blank or a comment
+func (e *yamlEditor) contentEnd(first int, end int) int {
+	for end > first+1 && e.isFiller(end-1) {
+		end--
+	}
+	return end
+}
+
+// headStart returns the first line of the comments directly above the line at the same
+// indentation, stopping at the floor
+func (e *yamlEditor) headStart(line int, column int, floor int) int {
+	for line > floor {
+		text := e.lineText(line - 1)
+		trimmed := strings.TrimLeft(text, " ")
+		if !strings.HasPrefix(trimmed, "#") || len(text)-len(trimmed) != column-1 {
+			break
+		}
+		line--
+	}
+	return line
+}
+
+func (e *yamlEditor) add(start int, end int, text string) {
+	e.edits = append(e.edits, yamlEdit{start: start, end: end, text: text})
+}
+
+// apply returns the source with the edits made
+func (e *yamlEditor) apply() ([]byte, error) {
+	sort.SliceStable(e.edits, func(i, j int) bool {
+		if e.edits[i].start != e.edits[j].start {
+			return e.edits[i].start < e.edits[j].start
+		}
+		return e.edits[i].end < e.edits[j].end
+	})
+
+	out := bytes.Buffer{}
+	at := 0
+	for _, edit := range e.edits {
+		if edit.start < at {
+			return nil, fmt.Errorf("overlapping yaml edits at offset %d", edit.start)
+		}
+		out.Write(e.source[at:edit.start])
+		out.WriteString(edit.text)
+		at = edit.end
+	}
+	out.Write(e.source[at:])
+	return out.Bytes(), nil
+}
+
+// render marshals the value in the sequence style of the source, indenting each line by
+// indent spaces. The first line is not indented when it continues a line
+func (e *yamlEditor) render(value interface{}, indent int, continues bool) (string, error) {
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	if e.indentedSequences {
+		node := yaml3.Node{}
+		if err := yaml3.Unmarshal(out, &node); err != nil {
+			return "", err
+		}
+
+		buf := bytes.Buffer{}
+		enc := yaml3.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&node); err != nil {
+			return "", err
+		}
+		if err := enc.Close(); err != nil {
+			return "", err
+		}
+		out = buf.Bytes()
+	}
+
+	lines := strings.SplitAfter(string(out), "\n")
+	prefix := strings.Repeat(" ", indent)
+	for i, line := range lines {
+		if line == "" || (i == 0 && continues) {
+			continue
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, ""), nil
+}
+
+// mapping edits the block mapping from its old to its wanted values, within the lines before
+// end. Keys keep their order, new keys are added after the key before them in the wanted
+// document, and a key removed where another is added is renamed. When the mapping is an item
+// of a sequence, its first key shares the line of the dash and can not be removed or have
+// a key added before it
+func (e *yamlEditor) mapping(node *yaml3.Node, old yaml.MapSlice, want yaml.MapSlice, end int, item bool) error {
+	if !isBlock(node, yaml3.MappingNode) || len(node.Content) != 2*len(old) || len(want) == 0 {
+		return errNotEditable
+	}
+
+	oldIndex := map[string]int{}
+	for i, pair := range old {
+		oldIndex[fmt.Sprint(pair.Key)] = i
+	}
+
+	// matched holds the wanted index of each old key, and position the old index of each
+	// wanted key, or -1 when there is none
+	matched := make([]int, len(old))
+	for i := range matched {
+		matched[i] = -1
+	}
+	position := make([]int, len(want))
+	for j, pair := range want {
+		position[j] = -1
+		if i, ok := oldIndex[fmt.Sprint(pair.Key)]; ok {
+			matched[i], position[j] = j, i
+		}
+	}
+	for j := range want {
+		if position[j] >= 0 {
+			continue
+		}
+		i := 0
+		if j > 0 {
+			if position[j-1] < 0 {
+				continue
+			}
+			i = position[j-1] + 1
+		}
+		if i < len(old) && matched[i] < 0 {
+			matched[i], position[j] = j, i
+		}
+	}
+
+	pairEnd := func(i int) int {
+		if i+1 < len(old) {
+			return node.Content[2*(i+1)].Line
+		}
+		return end
+	}
+
+	for i := range old {
+		key, value := node.Content[2*i], node.Content[2*i+1]
+		contentEnd := e.contentEnd(key.Line, pairEnd(i))
+
+		if matched[i] < 0 {
+			if item && i == 0 {
+				return errNotEditable
+			}
+			// the comments above the first top level key describe the file, so they are kept
+			floor := 1
+			if i > 0 {
+				floor = e.contentEnd(node.Content[2*(i-1)].Line, key.Line)
+			} else if key.Column == 1 {
+				floor = key.Line
+			}
+			e.add(e.lineStart(e.headStart(key.Line, key.Column, floor)), e.lineStart(contentEnd), "")
+			continue
+		}
+
+		wanted := want[matched[i]]
+		edits := len(e.edits)
+		err := e.renameKey(key, old[i].Key, wanted.Key)
+		if err == nil {
+			err = e.value(value, old[i].Value, wanted.Value, pairEnd(i))
+		}
+		if err != nil {
+			// the pair is written again with its new key and value
+			e.edits = e.edits[:edits]
+			start := e.lineStart(key.Line) + key.Column - 1
+			text, err := e.render(yaml.MapSlice{wanted}, key.Column-1, true)
+			if err != nil {
+				return err
+			}
+			e.add(start, e.lineStart(contentEnd), text)
+		}
+	}
+
+	for j := 0; j < len(want); {
+		if position[j] >= 0 {
+			j++
+			continue
+		}
+
+		// consecutive new keys are added together after the key before them
+		next := j
+		for next < len(want) && position[next] < 0 {
+			next++
+		}
+
+		at := e.lineStart(node.Content[0].Line)
+		if j == 0 && item {
+			return errNotEditable
+		}
+		if j > 0 {
+			i := position[j-1]
+			at = e.lineStart(e.contentEnd(node.Content[2*i].Line, pairEnd(i)))
+		}
+
+		// keys are aligned with the first key, which follows the dash of an item
+		text, err := e.render(want[j:next], node.Content[0].Column-1, false)
+		if err != nil {
+			return err
+		}
+		e.add(at, at, text)
+		j = next
+	}
+	return nil
+}
+
+// renameKey replaces the key when it has changed
+func (e *yamlEditor) renameKey(node *yaml3.Node, old interface{}, want interface{}) error {
+	if reflect.DeepEqual(old, want) {
+		return nil
+	}
+	return e.scalar(node, want)
+}
+
+// value edits the value from the old to the wanted value, within the lines before end
+func (e *yamlEditor) value(node *yaml3.Node, old interface{}, want interface{}, end int) error {
+	if reflect.DeepEqual(old, want) {
+		return nil
+	}
+
+	switch w := want.(type) {
+	case yaml.MapSlice:
+		o, ok := old.(yaml.MapSlice)
+		if !ok {
+			return errNotEditable
+		}
+		return e.mapping(node, o, w, end, false)
+	case []interface{}:
+		o, ok := old.([]interface{})
+		if !ok {
+			return errNotEditable
+		}
+		return e.sequence(node, o, w, end)
+	}
+
+	if old == nil || isCollection(old) {
+		return errNotEditable
+	}
+	return e.scalar(node, want)
+}
+
+func isCollection(value interface{}) bool {
+	switch value.(type) {
+	case yaml.MapSlice, []interface{}:
+		return true
+	}
+	return false
+}
+
+// scalar replaces the single line scalar, keeping its quoting when the new value is a string
+func (e *yamlEditor) scalar(node *yaml3.Node, want interface{}) error {
+	if node.Kind != yaml3.ScalarNode || node.Style&(yaml3.LiteralStyle|yaml3.FoldedStyle) != 0 {
+		return errNotEditable
+	}
+
+	line := e.lineText(node.Line)
+	start := columnOffset(line, node.Column)
+	if start < 0 {
+		return errNotEditable
+	}
+
+	length := scalarLength(line[start:], node)
+	if length < 0 {
+		return errNotEditable
+	}
+
+	replacement := yaml3.Node{}
+	if err := replacement.Encode(want); err != nil {
+		return err
+	}
+	if replacement.Kind != yaml3.ScalarNode {
+		return errNotEditable
+	}
+	if replacement.Tag == "!!str" && node.Style&(yaml3.DoubleQuotedStyle|yaml3.SingleQuotedStyle) != 0 {
+		replacement.Style = node.Style
+	}
+
+	out, err := yaml3.Marshal(&replacement)
+	if err != nil {
+		return err
+	}
+	text := strings.TrimSuffix(string(out), "\n")
+	if strings.Contains(text, "\n") {
+		return errNotEditable
+	}
+
+	offset := e.lineStart(node.Line) + start
+	e.add(offset, offset+length, text)
+	return nil
+}
+
+// columnOffset returns the byte offset of the column, counted in characters from 1, or -1
+// when the line is shorter
+func columnOffset(line string, column int) int {
+	n := 1
+	for offset := range line {
+		if n == column {
+			return offset
+		}
+		n++
+	}
+	return -1
+}
+
+// scalarLength returns the length of the scalar at the start of the text, or -1 when it does
+// not end on the line
+func scalarLength(text string, node *yaml3.Node) int {
+	switch {
+	case node.Style&yaml3.DoubleQuotedStyle != 0:
+		for i := 1; i < len(text); i++ {
+			if text[i] == '\\' {
+				i++
+			} else if text[i] == '"' {
+				return i + 1
+			}
+		}
+	case node.Style&yaml3.SingleQuotedStyle != 0:
+		for i := 1; i < len(text); i++ {
+			if text[i] != '\'' {
+				continue
+			}
+			if i+1 < len(text) && text[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	default:
+		if strings.HasPrefix(text, node.Value) {
+			return len(node.Value)
+		}
+	}
+	return -1
+}
+
+// sequenceKey returns the key the items of the sequences are matched by: their name when
+// every item is a mapping with a unique name, otherwise their position
+func sequenceKey(lists ...[]interface{}) func(int, interface{}) string {
+	byIndex := func(i int, _ interface{}) string { return fmt.Sprint(i) }
+	byName := func(_ int, item interface{}) string {
+		name, _ := getKey(item.(yaml.MapSlice), "name")
+		return fmt.Sprint(name)
+	}
+
+	for _, list := range lists {
+		names := map[string]bool{}
+		for _, item := range list {
+			m, ok := item.(yaml.MapSlice)
+			if !ok {
+				return byIndex
+			}
+			name, ok := getKey(m, "name")
+			if !ok || names[fmt.Sprint(name)] {
+				return byIndex
+			}
+			names[fmt.Sprint(name)] = true
+		}
+	}
+	return byName
+}
+
+// sequence edits the block sequence from its old to its wanted items, within the lines before
+// end. Items are matched by name, or by position, and new items are added after the item
+// before them in the wanted sequence
+func (e *yamlEditor) sequence(node *yaml3.Node, old []interface{}, want []interface{}, end int) error {
+	if !isBlock(node, yaml3.SequenceNode) || len(node.Content) != len(old) || len(old) == 0 || len(want) == 0 {
+		return errNotEditable
+	}
+
+	// each item must start on the line of its dash
+	dashes := make([]int, len(old))
+	for i, item := range node.Content {
+		line := e.lineText(item.Line)
+		if item.Column < 3 || item.Column-1 > len(line) {
+			return errNotEditable
+		}
+		before := strings.TrimRight(line[:item.Column-1], " ")
+		if !strings.HasSuffix(before, "-") || strings.TrimSpace(before) != "-" {
+			return errNotEditable
+		}
+		dashes[i] = len(before) - 1
+	}
+
+	key := sequenceKey(old, want)
+	oldIndex := map[string]int{}
+	for i, item := range old {
+		oldIndex[key(i, item)] = i
+	}
+	wantIndex := map[string]int{}
+	for j, item := range want {
+		wantIndex[key(j, item)] = j
+	}
+
+	itemEnd := func(i int) int {
+		if i+1 < len(old) {
+			return node.Content[i+1].Line
+		}
+		return end
+	}
+
+	for i, item := range node.Content {
+		contentEnd := e.contentEnd(item.Line, itemEnd(i))
+
+		j, ok := wantIndex[key(i, old[i])]
+		if !ok {
+			floor := 1
+			if i > 0 {
+				floor = e.contentEnd(node.Content[i-1].Line, item.Line)
+			}
+			e.add(e.lineStart(e.headStart(item.Line, dashes[i]+1, floor)), e.lineStart(contentEnd), "")
+			continue
+		}
+
+		edits := len(e.edits)
+		var err error
+		if m, ok := want[j].(yaml.MapSlice); ok {
+			if o, ok := old[i].(yaml.MapSlice); ok && !reflect.DeepEqual(o, m) {
+				err = e.mapping(item, o, m, itemEnd(i), true)
+			}
+		} else {
+			err = e.value(item, old[i], want[j], itemEnd(i))
+		}
+
+		if err != nil {
+			// the item is written again
+			e.edits = e.edits[:edits]
+			start := e.lineStart(item.Line) + dashes[i]
+			text, err := e.render([]interface{}{want[j]}, dashes[i], true)
+			if err != nil {
+				return err
+			}
+			e.add(start, e.lineStart(contentEnd), text)
+		}
+	}
+
+	for j := 0; j < len(want); {
+		if _, ok := oldIndex[key(j, want[j])]; ok {
+			j++
+			continue
+		}
+
+		next := j
+		for next < len(want) {
+			if _, ok := oldIndex[key(next, want[next])]; ok {
+				break
+			}
+			next++
+		}
+
+		at, dash := e.lineStart(node.Content[0].Line), dashes[0]
+		if j > 0 {
+			i := oldIndex[key(j-1, want[j-1])]
+			at, dash = e.lineStart(e.contentEnd(node.Content[i].Line, itemEnd(i))), dashes[i]
+		}
+
+		text, err := e.render(want[j:next], dash, false)
+		if err != nil {
+			return err
+		}
+		e.add(at, at, text)
+		j = next
+	}
+	return nil
+}
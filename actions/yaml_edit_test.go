@@ -0,0 +1,199 @@
+package actions
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+// initSource is an init.yml edited by hand, with comments, quoting and fields unknown to the wizard
+var initSource = `# OpenFaaS Cloud settings
+orchestration: kubernetes
+
+# secrets created by ofc-bootstrap
+secrets:
+# signs requests between functions
+- name: payload-secret
+  literals:
+  - name: payload-secret
+    value: "abc" # rotated yearly
+  filters:
+  - default
+  namespace: openfaas-fn
+  owner: platform
+- name: basic-auth
+  literals:
+  - name: basic-auth-user
+    value: admin
+  - name: basic-auth-password
+    value: 'secret'
+  filters: ["default"]
+root_domain: example.com # the public domain
+customers_url: https://example.com/CUSTOMERS
+`
+
+// editSource decodes the source, changes the document and returns the edited source
+func editSource(t *testing.T, source string, change func(doc yaml.MapSlice) yaml.MapSlice) string {
+	doc := yaml.MapSlice{}
+	if err := yaml.Unmarshal([]byte(source), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := editYaml([]byte(source), change(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+// secretAt returns the mapping of the secret with the name
+func secretAt(doc yaml.MapSlice, name string) yaml.MapSlice {
+	secrets, _ := getKey(doc, "secrets")
+	for _, s := range secrets.([]interface{}) {
+		if n, _ := getKey(s.(yaml.MapSlice), "name"); n == name {
+			return s.(yaml.MapSlice)
+		}
+	}
+	return nil
+}
+
+func Test_editYaml_Unchanged(t *testing.T) {
+	got := editSource(t, initSource, func(doc yaml.MapSlice) yaml.MapSlice { return doc })
+	if got != initSource {
+		t.Errorf("want the source unchanged, got:\n%s", got)
+	}
+}
+
+func Test_editYaml(t *testing.T) {
+	cases := []struct {
+		name   string
+		change func(doc yaml.MapSlice) yaml.MapSlice
+		from   string
+		to     string
+	}{
+		{
+			"scalar keeps its quotes and comment",
+			func(doc yaml.MapSlice) yaml.MapSlice {
+				literals, _ := getKey(secretAt(doc, "payload-secret"), "literals")
+				literals.([]interface{})[0].(yaml.MapSlice)[1].Value = "def"
+				return doc
+			},
+			`    value: "abc" # rotated yearly`,
+			`    value: "def" # rotated yearly`,
+		},
+		{
+			"top level value",
+			func(doc yaml.MapSlice) yaml.MapSlice { return setKey(doc, "root_domain", "example.org") },
+			"root_domain: example.com # the public domain\n",
+			"root_domain: example.org # the public domain\n",
+		},
+		{
+			"new key at the end",
+			func(doc yaml.MapSlice) yaml.MapSlice { return setKey(doc, "build_branch", "main") },
+			"customers_url: https://example.com/CUSTOMERS\n",
+			"customers_url: https://example.com/CUSTOMERS\nbuild_branch: main\n",
+		},
+		{
+			"renamed key",
+			func(doc yaml.MapSlice) yaml.MapSlice { return renameKey(doc, "root_domain", "domain") },
+			"root_domain: example.com # the public domain\n",
+			"domain: example.com # the public domain\n",
+		},
+		{
+			"removed key",
+			func(doc yaml.MapSlice) yaml.MapSlice { return renameKey(doc, "orchestration", "customers_url") },
+			"# OpenFaaS Cloud settings\norchestration: kubernetes\n",
+			"# OpenFaaS Cloud settings\n",
+		},
+		{
+			"removed secret and its comment",
+			func(doc yaml.MapSlice) yaml.MapSlice {
+				secrets, _ := getKey(doc, "secrets")
+				return setKey(doc, "secrets", secrets.([]interface{})[1:])
+			},
+			"secrets:\n# signs requests between functions\n- name: payload-secret\n  literals:\n  - name: payload-secret\n    value: \"abc\" # rotated yearly\n  filters:\n  - default\n  namespace: openfaas-fn\n  owner: platform\n- name: basic-auth\n",
+			"secrets:\n- name: basic-auth\n",
+		},
+		{
+			"new secret after the last",
+			func(doc yaml.MapSlice) yaml.MapSlice {
+				secrets, _ := getKey(doc, "secrets")
+				secret := yaml.MapSlice{{Key: "name", Value: "customers"}, {Key: "filters", Value: []interface{}{"customers"}}}
+				return setKey(doc, "secrets", append(secrets.([]interface{}), secret))
+			},
+			"  filters: [\"default\"]\nroot_domain",
+			"  filters: [\"default\"]\n- name: customers\n  filters:\n  - customers\nroot_domain",
+		},
+		{
+			"flow sequence written again",
+			func(doc yaml.MapSlice) yaml.MapSlice {
+				basicAuth := secretAt(doc, "basic-auth")
+				setKey(basicAuth, "filters", []interface{}{"default", "auth"})
+				return doc
+			},
+			"  filters: [\"default\"]\n",
+			"  filters:\n  - default\n  - auth\n",
+		},
+		{
+			"removed literal",
+			func(doc yaml.MapSlice) yaml.MapSlice {
+				basicAuth := secretAt(doc, "basic-auth")
+				literals, _ := getKey(basicAuth, "literals")
+				setKey(basicAuth, "literals", literals.([]interface{})[:1])
+				return doc
+			},
+			"    value: admin\n  - name: basic-auth-password\n    value: 'secret'\n",
+			"    value: admin\n",
+		},
+	}
+
+	for _, c := range cases {
+		want := replaceOnce(t, c.name, initSource, c.from, c.to)
+		if got := editSource(t, initSource, c.change); got != want {
+			t.Errorf("%s: want:\n%s\ngot:\n%s", c.name, want, got)
+		}
+	}
+}
+
+func replaceOnce(t *testing.T, name string, source string, from string, to string) string {
+	i := indexOnce(source, from)
+	if i < 0 {
+		t.Fatalf("%s: %q is not in the source once", name, from)
+	}
+	return source[:i] + to + source[i+len(from):]
+}
+
+func indexOnce(source string, s string) int {
+	first := -1
+	for i := 0; i+len(s) <= len(source); i++ {
+		if source[i:i+len(s)] == s {
+			if first >= 0 {
+				return -1
+			}
+			first = i
+		}
+	}
+	return first
+}
+
+func Test_editYaml_IndentedSequences(t *testing.T) {
+	source := "secrets:\n  - name: a\n    filters:\n      - default\n"
+	want := "secrets:\n  - name: a\n    filters:\n      - default\n  - name: b\n    filters:\n      - auth\n"
+
+	got := editSource(t, source, func(doc yaml.MapSlice) yaml.MapSlice {
+		secrets, _ := getKey(doc, "secrets")
+		secret := yaml.MapSlice{{Key: "name", Value: "b"}, {Key: "filters", Value: []interface{}{"auth"}}}
+		return setKey(doc, "secrets", append(secrets.([]interface{}), secret))
+	})
+	if got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func Test_editYaml_Anchors(t *testing.T) {
+	source := "a: &v 1\nb: *v\n"
+	got := editSource(t, source, func(doc yaml.MapSlice) yaml.MapSlice { return setKey(doc, "a", 2) })
+	if got != "a: 2\nb: 1\n" {
+		t.Errorf("want the document written again, got:\n%s", got)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2019 Burton Rheutan
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/burtonr/ofc-wizard/actions"
+	"github.com/spf13/cobra"
+)
+
+var (
+	customersFile   string
+	customersSCM    string
+	customersOutput string
+)
+
+// customersCmd represents the customers command
+var customersCmd = &cobra.Command{
+	Use:   "customers",
+	Short: "Creates and checks the customers access control list",
+	Long: `The customers list names the users or organisations allowed to deploy
+functions to OpenFaaS Cloud, one per line. Lines starting with # are comments.
+
+Names are checked against the rules of the source control management, read
+from the init.yml file unless --scm is given.`,
+}
+
+// customersCreateCmd represents the customers create command
+var customersCreateCmd = &cobra.Command{
+	Use:   "create <name>...",
+	Short: "Writes a customers list with the given users or organisations",
+	Long: `Writes a customers list to --output with each of the users or
+organisations given, after checking the names are valid and not repeated.
+
+The file can be published as the customers_url, or read into the
+customers secret by setting customers_secret.`,
+	Example: `  ofc-wizard customers create alexellis openfaas
+  ofc-wizard customers create example-group --scm gitlab --output customers`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.CreateCustomers(customersFile, customersSCM, args, customersOutput)
+	},
+}
+
+// customersCheckCmd represents the customers check command
+var customersCheckCmd = &cobra.Command{
+	Use:   "check <path>",
+	Short: "Checks a customers list for invalid or repeated names",
+	Example: `  ofc-wizard customers check customers
+  ofc-wizard customers check file:///home/user/customers`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.CheckCustomers(customersFile, customersSCM, args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(customersCmd)
+	customersCmd.AddCommand(customersCreateCmd)
+	customersCmd.AddCommand(customersCheckCmd)
+
+	customersCmd.PersistentFlags().StringVar(&customersFile, "file", "init.yml", "the init.yml file to read the source control management from")
+	customersCmd.PersistentFlags().StringVar(&customersSCM, "scm", "", "the source control management the names are for (github or gitlab)")
+	customersCreateCmd.Flags().StringVar(&customersOutput, "output", "customers", "the file to write the customers list to")
+}
@@ -0,0 +1,44 @@
+/*
+Copyright © 2019 Burton Rheutan
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/burtonr/ofc-wizard/actions"
+	"github.com/spf13/cobra"
+)
+
+var diffOutput string
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <from.yml> <to.yml>",
+	Short: "Shows the field level changes between two init.yml files",
+	Long: `Compares two init.yml files field by field, ignoring formatting and
+ordering, and lists each added, removed or changed value by its path.
+Secrets are identified by name and their values are redacted.`,
+	Example: `  ofc-wizard diff init.yml new-init.yml
+  ofc-wizard diff init.yml new-init.yml --output json`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.DiffInitFiles(args[0], args[1], diffOutput)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "text", "output format (text or json)")
+}
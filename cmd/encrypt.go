@@ -0,0 +1,65 @@
+/*
+Copyright © 2019 Burton Rheutan
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/burtonr/ofc-wizard/actions"
+	"github.com/spf13/cobra"
+)
+
+var (
+	encryptFile   string
+	decryptOutput string
+)
+
+// encryptCmd represents the encrypt command
+var encryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypts the literal secret values of an init.yml file",
+	Long: `Encrypts each literal secret value of the init.yml file with a key
+derived from a passphrase, so the file can be committed. The passphrase is
+read from OFC_WIZARD_PASSPHRASE, or asked for.
+
+Encrypted values are decrypted when the file is loaded by the other commands,
+and encrypted again when it is written.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.EncryptInitFile(encryptFile)
+	},
+}
+
+// decryptCmd represents the decrypt command
+var decryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Writes an init.yml file with its secret values decrypted",
+	Long: `Decrypts each encrypted literal secret value of the init.yml file and
+writes the plaintext file to be used with ofc-bootstrap, to stdout or the file
+given with --output. The passphrase is read from OFC_WIZARD_PASSPHRASE, or
+asked for.`,
+	Example: `  ofc-wizard decrypt --output /tmp/init.yml
+  OFC_WIZARD_PASSPHRASE=... ofc-wizard decrypt > /tmp/init.yml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.DecryptInitFile(encryptFile, decryptOutput)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(encryptCmd)
+	rootCmd.AddCommand(decryptCmd)
+
+	encryptCmd.Flags().StringVar(&encryptFile, "file", "init.yml", "the init.yml file to encrypt")
+	decryptCmd.Flags().StringVar(&encryptFile, "file", "init.yml", "the init.yml file to decrypt")
+	decryptCmd.Flags().StringVarP(&decryptOutput, "output", "o", "", "the file to write the plaintext init.yml to (default stdout)")
+}
@@ -0,0 +1,39 @@
+/*
+Copyright © 2019 Burton Rheutan
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/burtonr/ofc-wizard/actions"
+	"github.com/spf13/cobra"
+)
+
+// explainValuesCmd represents the explain-values command
+var explainValuesCmd = &cobra.Command{
+	Use:   "explain-values",
+	Short: "Shows where each value of the init.yml file came from",
+	Long: `Lists every value of the init.yml file in the current directory along
+with where the wizard got it from: a prompt, a --set flag, an environment
+variable, the config file, an existing init.yml or a built-in default.
+
+Secret values are redacted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.ExplainValues()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainValuesCmd)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright © 2019 Burton Rheutan
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/burtonr/ofc-wizard/actions"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFile    string
+	exportFormat  string
+	exportFilters []string
+	exportDryRun  bool
+	exportActive  bool
+	dnsFormat     string
+	dnsAddress    string
+	githubOrg     string
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports resources described by an init.yml file",
+	Long: `Exports the resources described by an init.yml file so they can be
+managed outside of ofc-bootstrap, for example with GitOps.`,
+}
+
+// exportSecretsCmd represents the export secrets command
+var exportSecretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Exports the secrets of an init.yml file as manifests or a script",
+	Long: `Resolves the literals, value_from files and value_command outputs of
+each secret in the init.yml file and writes them to stdout.
+
+With --format k8s a Kubernetes Secret manifest is written for each secret.
+Secrets without a namespace are created where ofc-bootstrap creates them:
+openfaas-fn for the secrets read by functions, such as the payload-secret,
+registry-secret and the Github and GitLab secrets, cert-manager for the DNS
+provider credentials and openfaas for the rest.
+
+With --format swarm a shell script is written which runs docker secret create
+for each literal and file, as Docker Swarm secrets hold a single value.
+
+Use --dry-run to list what would be created without resolving any values.`,
+	Example: `  ofc-wizard export secrets --format k8s > secrets.yml
+  ofc-wizard export secrets --filter default,scm_github
+  ofc-wizard export secrets --active
+  ofc-wizard export secrets --format swarm > create-secrets.sh
+  ofc-wizard export secrets --format swarm --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.ExportSecrets(exportFile, exportFormat, exportFilters, exportActive, exportDryRun, os.Stdout)
+	},
+}
+
+// exportDNSCmd represents the export dns command
+var exportDNSCmd = &cobra.Command{
+	Use:   "dns",
+	Short: "Exports the DNS records needed by an init.yml file",
+	Long: `Lists the DNS records to create for the root domain of the init.yml file:
+a wildcard record for the user functions, system.<domain> for the dashboard
+and, when OAuth is enabled, auth.system.<domain> for the login.
+
+The records point to the --address given, as A, AAAA or CNAME records for an
+IPv4 address, IPv6 address or host name. Without an address a placeholder
+describing it is used, based on the ingress type.
+
+Use --format bind for a zone file fragment, or --format json.`,
+	Example: `  ofc-wizard export dns
+  ofc-wizard export dns --format bind --address 203.0.113.10 >> example.com.zone
+  ofc-wizard export dns --format json --address lb.example.net`,
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.ExportDNS(exportFile, dnsFormat, dnsAddress, os.Stdout)
+	},
+}
+
+// exportGitLabChecklistCmd represents the export gitlab-checklist command
+var exportGitLabChecklistCmd = &cobra.Command{
+	Use:   "gitlab-checklist",
+	Short: "Exports the GitLab setup steps for an init.yml file as Markdown",
+	Long: `Writes a checklist of the steps to set up GitLab for the init.yml file:
+the system hook URL and secret token, the OAuth application redirect URI and
+scopes when OAuth is enabled, and how projects are deployed.`,
+	Example: `  ofc-wizard export gitlab-checklist > gitlab-setup.md`,
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.ExportGitLabChecklist(exportFile, os.Stdout)
+	},
+}
+
+// exportGitHubAppCmd represents the export github-app command
+var exportGitHubAppCmd = &cobra.Command{
+	Use:   "github-app",
+	Short: "Exports a GitHub App manifest for an init.yml file",
+	Long: `Writes github-app-manifest.json, a GitHub App manifest with the webhook
+URL, permissions and events OpenFaaS Cloud needs, and github-app.html, a
+page which creates the app from the manifest with one click.
+
+The files are generated without contacting GitHub. Use --org to create the
+app in an organisation instead of your user account.`,
+	Example: `  ofc-wizard export github-app
+  ofc-wizard export github-app --org example`,
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.ExportGitHubApp(exportFile, githubOrg)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportSecretsCmd)
+	exportCmd.AddCommand(exportDNSCmd)
+	exportCmd.AddCommand(exportGitLabChecklistCmd)
+	exportCmd.AddCommand(exportGitHubAppCmd)
+
+	exportCmd.PersistentFlags().StringVar(&exportFile, "file", "init.yml", "the init.yml file to export from")
+	exportSecretsCmd.Flags().StringVar(&exportFormat, "format", "k8s", "the format of the exported secrets (k8s or swarm)")
+	exportSecretsCmd.Flags().BoolVar(&exportActive, "active", false, "only export secrets with a filter enabled by the init.yml settings")
+	exportSecretsCmd.Flags().BoolVar(&exportDryRun, "dry-run", false, "list the secrets that would be created without their values")
+	exportSecretsCmd.Flags().StringSliceVar(&exportFilters, "filter", nil, "only export secrets with one of these filters")
+	exportDNSCmd.Flags().StringVar(&dnsFormat, "format", "text", "the format of the records (text, bind or json)")
+	exportDNSCmd.Flags().StringVar(&dnsAddress, "address", "", "the ip address or host name the records point to")
+	exportGitHubAppCmd.Flags().StringVar(&githubOrg, "org", "", "the organisation to create the app in")
+}
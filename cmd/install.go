@@ -16,10 +16,15 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/burtonr/ofc-wizard/actions"
 	"github.com/spf13/cobra"
 )
 
+var setValues []string
+
 // generateCmd represents the install command
 var generateCmd = &cobra.Command{
 	Use:   "generate",
@@ -28,8 +33,18 @@ var generateCmd = &cobra.Command{
 the init.yml file used by the OpenFaaS Cloud bootstrap tool.
 
 The wizard will ask relevant questions and adjust as you enter your values
-to ensure that your new OpenFaaS Cloud installation will be successful!`,
+to ensure that your new OpenFaaS Cloud installation will be successful!
+
+Any answer may be given ahead of time, in order of precedence, with the
+--set flag, an OFC_WIZARD_<KEY> environment variable or the config file.
+Values from an existing init.yml are offered as the default answer.`,
+	Example: `  ofc-wizard generate --set root_domain=faas.example.com --set scm=github
+  OFC_WIZARD_GITHUB_APP_ID=1234 ofc-wizard generate`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if err := actions.SetAnswerOverrides(setValues); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 		actions.GenerateYaml()
 	},
 }
@@ -37,6 +52,8 @@ to ensure that your new OpenFaaS Cloud installation will be successful!`,
 func init() {
 	rootCmd.AddCommand(generateCmd)
 
+	generateCmd.Flags().StringArrayVar(&setValues, "set", nil, "answer a question without prompting (key=value), may be repeated")
+
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
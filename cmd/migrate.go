@@ -0,0 +1,53 @@
+/*
+Copyright © 2019 Burton Rheutan
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/burtonr/ofc-wizard/actions"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateFile      string
+	migrateToVersion string
+	migrateYes       bool
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrades an init.yml file to a newer version of OpenFaaS Cloud",
+	Long: `Applies each of the changes to the init.yml file needed by the
+ofc-bootstrap release for the target OpenFaaS Cloud version, such as
+renamed fields, new required secrets and changed defaults.
+
+Only the new required values are asked for, and the changes are printed
+for review before the file is written.`,
+	Example: `  ofc-wizard migrate --to 0.10.0
+  ofc-wizard migrate --file ./prod/init.yml --to 0.10.0`,
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.MigrateInitFile(migrateFile, migrateToVersion, migrateYes)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().StringVar(&migrateFile, "file", "init.yml", "the init.yml file to migrate")
+	migrateCmd.Flags().StringVar(&migrateToVersion, "to", "", "the OpenFaaS Cloud version to migrate to")
+	migrateCmd.Flags().BoolVarP(&migrateYes, "yes", "y", false, "write the changes without asking for confirmation, using the default for new values which are not given")
+	migrateCmd.MarkFlagRequired("to")
+}
@@ -0,0 +1,147 @@
+/*
+Copyright © 2019 Burton Rheutan
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/burtonr/ofc-wizard/actions"
+	"github.com/spf13/cobra"
+)
+
+var (
+	secretsFile        string
+	secretsValues      actions.SecretValues
+	secretsListFilters []string
+)
+
+// secretsCmd represents the secrets command
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manages the secrets of an init.yml file",
+}
+
+// secretsListCmd represents the secrets list command
+var secretsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "Lists which secrets ofc-bootstrap will create",
+	Long: `Works out the feature filters enabled by the init.yml settings and lists
+which secrets will be created, which will be skipped and which required
+secrets are missing.
+
+The filters are:
+  default        always
+  scm_github     scm: github
+  scm_gitlab     scm: gitlab
+  auth           enable_oauth: true
+  ecr            enable_ecr: true
+  customers      customers_secret: true
+  s3             custom S3 storage (s3.s3_url is set)
+  do_dns01       TLS with DigitalOcean
+  gcp_dns01      TLS with Google Cloud
+  route53_dns01  TLS with AWS Route 53
+
+Use --filter to only list the secrets with, or required by, the given
+filters.`,
+	Example: `  ofc-wizard secrets list
+  ofc-wizard secrets list --filter s3`,
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.ListSecrets(secretsFile, secretsListFilters, os.Stdout)
+	},
+}
+
+// secretsAddCmd represents the secrets add command
+var secretsAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Adds a new secret",
+	Long: `Adds a new secret to the init.yml file. Keys may be given with the
+--literal, --from-file and --from-command flags, otherwise they are asked for,
+with literal values entered without being shown.`,
+	Example: `  ofc-wizard secrets add slack-token
+  ofc-wizard secrets add slack-token --literal token=abc123 --filter default
+  ofc-wizard secrets add private-key --from-file private-key=~/Downloads/app.pem --filter scm_github`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.AddSecret(secretsFile, args[0], secretsValues)
+	},
+}
+
+// secretsSetCmd represents the secrets set command
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Changes the keys, filters or namespace of a secret",
+	Long: `Changes an existing secret in the init.yml file. Keys given with the
+--literal, --from-file and --from-command flags replace any key of the same
+name. When no changes are given, the key to change is asked for.`,
+	Example: `  ofc-wizard secrets set payload-secret
+  ofc-wizard secrets set basic-auth --literal basic-auth-user=admin`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.SetSecret(secretsFile, args[0], secretsValues)
+	},
+}
+
+// secretsRemoveCmd represents the secrets rm command
+var secretsRemoveCmd = &cobra.Command{
+	Use:     "rm <name> [key]",
+	Aliases: []string{"remove"},
+	Short:   "Removes a secret, or one key of a secret",
+	Args:    cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		key := ""
+		if len(args) > 1 {
+			key = args[1]
+		}
+		actions.RemoveSecret(secretsFile, args[0], key)
+	},
+}
+
+// secretsRotateCmd represents the secrets rotate command
+var secretsRotateCmd = &cobra.Command{
+	Use:   "rotate <name>",
+	Short: "Generates a new value for a secret",
+	Long: `Generates a new value for a secret whose value is generated, such as the
+payload-secret, github-webhook-secret, gitlab-webhook-secret or the basic-auth
+password. The value is updated in the init.yml file, or in the file it is
+read from, and the previous value is kept in .ofc-wizard-rotations.yml.
+
+The steps needed to start using the new value are printed afterwards.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.RotateSecret(secretsFile, args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsListCmd)
+	secretsCmd.AddCommand(secretsAddCmd)
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsRemoveCmd)
+	secretsCmd.AddCommand(secretsRotateCmd)
+
+	secretsCmd.PersistentFlags().StringVar(&secretsFile, "file", "init.yml", "the init.yml file with the secrets")
+	secretsListCmd.Flags().StringSliceVar(&secretsListFilters, "filter", nil, "only list the secrets with, or required by, one of these filters")
+
+	for _, c := range []*cobra.Command{secretsAddCmd, secretsSetCmd} {
+		c.Flags().StringArrayVar(&secretsValues.Literals, "literal", nil, "a literal key (name=value), may be repeated")
+		c.Flags().StringArrayVar(&secretsValues.Files, "from-file", nil, "a key read from a file (name=path), may be repeated")
+		c.Flags().StringArrayVar(&secretsValues.Commands, "from-command", nil, "a key read from the file of the same name written by a command (name=command), may be repeated")
+		c.Flags().StringSliceVar(&secretsValues.Filters, "filter", nil, "the filters which enable the secret")
+		c.Flags().StringVar(&secretsValues.Namespace, "namespace", "", "the namespace of the secret")
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright © 2019 Burton Rheutan
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/burtonr/ofc-wizard/actions"
+	"github.com/spf13/cobra"
+)
+
+var (
+	testAuditFile    string
+	testAuditURL     string
+	testAuditMessage string
+)
+
+// testAuditCmd represents the test-audit command
+var testAuditCmd = &cobra.Command{
+	Use:   "test-audit",
+	Short: "Posts a sample audit event to the audit trail",
+	Long: `Posts a sample audit event to the --url given, or to the slack.url of the
+init.yml file, and prints the response.
+
+A Slack incoming webhook is sent the event as a message. Any other endpoint,
+such as the echo function, is sent the event as JSON. The built-in echo
+function is only reachable inside the cluster, so give a --url to test it,
+for example through a port-forward to the gateway.`,
+	Example: `  ofc-wizard test-audit
+  kubectl port-forward -n openfaas svc/gateway 8080:8080 &
+  ofc-wizard test-audit --url http://127.0.0.1:8080/function/echo`,
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.TestAudit(testAuditFile, testAuditURL, testAuditMessage, os.Stdout)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(testAuditCmd)
+
+	testAuditCmd.Flags().StringVar(&testAuditFile, "file", "init.yml", "the init.yml file to read the audit trail URL from")
+	testAuditCmd.Flags().StringVar(&testAuditURL, "url", "", "the endpoint to post to instead of the audit trail URL of the init.yml")
+	testAuditCmd.Flags().StringVar(&testAuditMessage, "message", "Test audit event from ofc-wizard", "the message of the sample event")
+}
@@ -0,0 +1,44 @@
+/*
+Copyright © 2019 Burton Rheutan
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/burtonr/ofc-wizard/actions"
+	"github.com/spf13/cobra"
+)
+
+var validateFile string
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Checks an init.yml file for problems",
+	Long: `Checks that each field of the init.yml file is supported by its
+openfaas_cloud_version, and that no Kubernetes only settings, such as
+network policies, ingress and TLS, are used with Docker Swarm.
+
+The root domain, registry, GitLab, ECR, audit trail, customers, custom
+template and Vault settings are checked as well.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		actions.ValidateInitFile(validateFile)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().StringVar(&validateFile, "file", "init.yml", "the init.yml file to validate")
+}
@@ -0,0 +1,14 @@
+package types
+
+// AuditEvent is the message OpenFaaS Cloud posts to the audit trail
+type AuditEvent struct {
+	Source  string `json:"source"`
+	Message string `json:"message"`
+	Owner   string `json:"owner,omitempty"`
+	Repo    string `json:"repo,omitempty"`
+}
+
+// SlackMessage is the body of a message posted to a Slack incoming webhook
+type SlackMessage struct {
+	Text string `json:"text"`
+}
@@ -0,0 +1,11 @@
+package types
+
+type DockerConfig struct {
+	Auths       map[string]DockerAuth `json:"auths"`
+	CredsStore  string                `json:"credsStore,omitempty"`
+	CredHelpers map[string]string     `json:"credHelpers,omitempty"`
+}
+
+type DockerAuth struct {
+	Auth string `json:"auth,omitempty"`
+}
@@ -0,0 +1,18 @@
+package types
+
+type GitHubAppManifest struct {
+	Name               string            `json:"name"`
+	URL                string            `json:"url"`
+	Description        string            `json:"description"`
+	HookAttributes     HookAttributes    `json:"hook_attributes"`
+	RedirectURL        string            `json:"redirect_url"`
+	CallbackURLs       []string          `json:"callback_urls,omitempty"`
+	Public             bool              `json:"public"`
+	DefaultPermissions map[string]string `json:"default_permissions"`
+	DefaultEvents      []string          `json:"default_events"`
+}
+
+type HookAttributes struct {
+	URL    string `json:"url"`
+	Active bool   `json:"active"`
+}
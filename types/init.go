@@ -12,7 +12,8 @@ type InitYaml struct {
 	GitLab               GitLab         `yaml:"gitlab"`
 	OAuth                OAuth          `yaml:"oauth"`
 	Slack                Slack          `yaml:"slack"`
-	CustomersURL         string         `yaml:"cusomter_url"`
+	CustomersURL         string         `yaml:"customers_url"`
+	CustomersSecret      bool           `yaml:"customers_secret,omitempty"`
 	S3                   Storage        `yaml:"s3"`
 	EnableOAuth          bool           `yaml:"enable_oauth"`
 	TLS                  bool           `yaml:"tls"`
@@ -21,6 +22,13 @@ type InitYaml struct {
 	ScaleToZero          bool           `yaml:"scale_to_zero"`
 	OpenFaaSCloudVersion string         `yaml:"openfaas_cloud_version"`
 	NetworkPolicies      bool           `yaml:"network_policies"`
+	BuildBranch          string         `yaml:"build_branch,omitempty"`
+	EnableECR            bool           `yaml:"enable_ecr,omitempty"`
+	ECRConfig            ECRConfig      `yaml:"ecr_config,omitempty"`
+	SchemaVersion        string         `yaml:"schema_version,omitempty"`
+
+	// LegacyCustomersURL is the misspelt customers_url written by older versions of the wizard
+	LegacyCustomersURL string `yaml:"cusomter_url,omitempty"`
 }
 
 type Secret struct {
@@ -63,6 +71,10 @@ type Slack struct {
 	URL string `yaml:"url"`
 }
 
+type ECRConfig struct {
+	ECRRegion string `yaml:"ecr_region"`
+}
+
 type Storage struct {
 	S3URL    string `yaml:"s3_url"`
 	S3Region string `yaml:"s3_region"`